@@ -1,36 +1,58 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"fri-flowser-playground/internal/git"
 	"fri-flowser-playground/internal/project"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/rs/cors"
 	"github.com/rs/zerolog"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
-var currentProject *project.Project
+var projects *project.Registry
 var logger *zerolog.Logger
-var logCache *CacheLogWriter
+var logOutput io.Writer
 var port = 8080
+var projectIdleTimeout = 30 * time.Minute
 
 func main() {
-	logger, logCache = initLogger()
+	dataDirectory := flag.String("data-dir", "", "directory to persist projects to, projects are kept in memory if empty")
+	flag.Parse()
+
+	logger, logOutput = initLogger()
+
+	projects = project.NewRegistry(logger, logOutput, projectIdleTimeout, *dataDirectory)
+
+	err := projects.RestoreAll()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to restore projects")
+	}
+
+	stopEviction := projects.StartEviction(time.Minute)
+	defer stopEviction()
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/projects", projectsHandler)
-	mux.HandleFunc("/projects/files", projectFilesHandler)
-	mux.HandleFunc("/projects/logs", projectLogsHandler)
-	mux.HandleFunc("/projects/blockchain-state", blockchainStateHandler)
-	mux.HandleFunc("/projects/transactions", transactionsHandler)
-	mux.HandleFunc("/projects/scripts", scriptsHandler)
+	mux.HandleFunc("/projects/", projectHandler)
+	mux.HandleFunc("/projects/import", importProjectHandler)
 
-	corsHandler := cors.Default().Handler(mux)
+	corsHandler := cors.New(cors.Options{
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+	}).Handler(mux)
 	logger.Info().Msgf("Server is running at http://localhost:%d", port)
 
-	err := http.ListenAndServe(fmt.Sprintf(":%d", port), corsHandler)
+	err = http.ListenAndServe(fmt.Sprintf(":%d", port), corsHandler)
 
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to start server")
@@ -38,123 +60,362 @@ func main() {
 
 }
 
-func transactionsHandler(w http.ResponseWriter, r *http.Request) {
+// projectHandler routes requests of the form /projects/{id}/{resource} to the resource handlers.
+func projectHandler(w http.ResponseWriter, r *http.Request) {
+	id, resourcePath, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/projects/"), "/")
+	resource, subPath, _ := strings.Cut(resourcePath, "/")
+
+	// Deleting doesn't restore evicted projects, so that projects failing to restore can be deleted as well.
+	if resource == "" && r.Method == "DELETE" {
+		deleteProjectHandler(w, r, id)
+		return
+	}
+
+	currentProject, err := projects.Get(id)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch resource {
+	case "":
+		projectDetailHandler(w, r, currentProject)
+	case "files":
+		projectFilesHandler(w, r, currentProject, subPath)
+	case "deploy":
+		deployHandler(w, r, currentProject)
+	case "status":
+		statusHandler(w, r, currentProject)
+	case "diff":
+		diffHandler(w, r, currentProject)
+	case "checkout":
+		checkoutHandler(w, r, currentProject)
+	case "commits":
+		commitsHandler(w, r, currentProject)
+	case "push":
+		pushHandler(w, r, currentProject)
+	case "logs":
+		projectLogsHandler(w, r, currentProject, subPath)
+	case "blockchain-state":
+		blockchainStateHandler(w, r, currentProject)
+	case "chain":
+		chainHandler(w, r, currentProject, subPath)
+	case "accounts":
+		accountsHandler(w, r, currentProject, subPath)
+	case "transactions":
+		transactionsHandler(w, r, currentProject, subPath)
+	case "scripts":
+		scriptsHandler(w, r, currentProject, subPath)
+	case "parameters":
+		parametersHandler(w, r, currentProject, subPath)
+	case "snapshots":
+		snapshotsHandler(w, r, currentProject, subPath)
+	case "export":
+		exportHandler(w, r, currentProject)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func projectDetailHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	switch r.Method {
+	case "GET":
+		getProjectHandler(w, r, currentProject)
+	case "PATCH":
+		patchProjectHandler(w, r, currentProject)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func getProjectHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	writeProjectInfo(w, http.StatusOK, currentProject)
+}
+
+// writeProjectInfo responds with the project details, or with an error if the project was closed in the meantime.
+func writeProjectInfo(w http.ResponseWriter, status int, currentProject *project.Project) {
+	info, err := currentProject.Info()
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeJson(w, status, info)
+}
+
+type PatchProjectRequest struct {
+	AutoDeploy *bool `json:"autoDeploy"`
+	// Start or stop serving the project chain through the Flow Access gRPC and REST APIs.
+	AccessAPI *bool `json:"accessApi"`
+}
+
+func patchProjectHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	var request PatchProjectRequest
+	if err := readJson(r, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if request.AutoDeploy != nil {
+		err := currentProject.SetAutoDeploy(*request.AutoDeploy)
+		if err != nil {
+			writeProjectError(w, err)
+			return
+		}
+	}
+
+	if request.AccessAPI != nil {
+		err := currentProject.SetAccessAPI(*request.AccessAPI)
+		if err != nil {
+			writeProjectError(w, err)
+			return
+		}
+	}
+
+	writeProjectInfo(w, http.StatusOK, currentProject)
+}
+
+func deleteProjectHandler(w http.ResponseWriter, r *http.Request, id string) {
+	err := projects.Delete(id)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// transactionsHandler executes the transaction from the request body,
+// or from the repository file if the request path continues with a file path.
+func transactionsHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
 	switch r.Method {
 	case "POST":
-		createTransactionHandler(w, r)
+		createTransactionHandler(w, r, currentProject, filePath)
 	default:
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	}
 }
 
+// CreateTransactionRequest describes the transaction to execute.
+// Source and location are ignored when executing a repository file.
 type CreateTransactionRequest struct {
 	Source    string `json:"source"`
 	Location  string `json:"location"`
 	Arguments string `json:"arguments"`
+	// Authorizers are account names, one for each prepare() parameter.
+	Authorizers []string `json:"authorizers"`
+	Proposer    string   `json:"proposer"`
+	Payer       string   `json:"payer"`
+	// Maximum computation the transaction may use, defaults to the Flow SDK default limit.
+	ComputeLimit uint64 `json:"computeLimit"`
+	// Simplified arguments keyed by parameter name (e.g. {"amount": "10.0"}), used instead of arguments if set.
+	NamedArguments map[string]json.RawMessage `json:"namedArguments"`
 }
 
-func createTransactionHandler(w http.ResponseWriter, r *http.Request) {
-	if currentProject == nil {
-		http.Error(w, "Project not created", http.StatusBadRequest)
-		return
-	}
-
+func createTransactionHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, "Error reading request body", http.StatusInternalServerError)
 		return
 	}
 
+	// The body is optional when executing a file that doesn't take any arguments.
 	var request CreateTransactionRequest
-	if err := json.Unmarshal(body, &request); err != nil {
-		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+	if len(body) > 0 || filePath == "" {
+		if err := json.Unmarshal(body, &request); err != nil {
+			http.Error(w, "Error parsing request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	options := project.TransactionOptions{
+		Authorizers:    request.Authorizers,
+		Proposer:       request.Proposer,
+		Payer:          request.Payer,
+		ComputeLimit:   request.ComputeLimit,
+		NamedArguments: request.NamedArguments,
+	}
+
+	var result *project.TransactionResult
+	if filePath != "" {
+		result, err = currentProject.ExecuteTransactionFile(filePath, request.Arguments, options)
+	} else {
+		result, err = currentProject.ExecuteTransaction([]byte(request.Source), request.Location, request.Arguments, options)
+	}
+
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, git.ErrSymlink) {
+		writeFileError(w, err)
 		return
 	}
 
-	result, err := currentProject.ExecuteTransaction([]byte(request.Source), request.Location, request.Arguments)
+	var cadenceError *project.CadenceError
+	if errors.Is(err, project.ErrInvalidTransaction) || errors.As(err, &cadenceError) {
+		writeError(w, http.StatusBadRequest, "invalid_transaction", err)
+		return
+	}
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if errors.Is(err, project.ErrInvalidArguments) {
+		writeError(w, http.StatusBadRequest, "invalid_arguments", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_, err = w.Write(result)
+	if errors.Is(err, project.ErrUnresolvedImports) {
+		writeError(w, http.StatusUnprocessableEntity, "unresolved_imports", err)
+		return
+	}
 
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to write response")
+		writeProjectError(w, err)
+		return
 	}
+
+	writeJson(w, http.StatusCreated, result)
 }
 
-func scriptsHandler(w http.ResponseWriter, r *http.Request) {
+// scriptsHandler executes the script from the request body,
+// or from the repository file if the request path continues with a file path.
+func scriptsHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
 	switch r.Method {
 	case "POST":
-		createScriptHandler(w, r)
+		createScriptHandler(w, r, currentProject, filePath)
 	default:
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	}
 }
 
+// CreateScriptRequest describes the script to execute.
+// Source and location are ignored when executing a repository file.
 type CreateScriptRequest struct {
 	Source    string `json:"source"`
 	Location  string `json:"location"`
 	Arguments string `json:"arguments"`
+	// Height of the block to execute the script at, defaults to the latest block.
+	BlockHeight *uint64 `json:"blockHeight"`
+	// Also return the result as plain JSON, in addition to JSON-Cadence.
+	Simplified bool `json:"simplified"`
+	// Simplified arguments keyed by parameter name (e.g. {"amount": "10.0"}), used instead of arguments if set.
+	NamedArguments map[string]json.RawMessage `json:"namedArguments"`
 }
 
-func createScriptHandler(w http.ResponseWriter, r *http.Request) {
-	if currentProject == nil {
-		http.Error(w, "Project not created", http.StatusBadRequest)
-		return
-	}
-
+func createScriptHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, "Error reading request body", http.StatusInternalServerError)
 		return
 	}
 
+	// The body is optional when executing a file that doesn't take any arguments.
 	var request CreateScriptRequest
-	if err := json.Unmarshal(body, &request); err != nil {
-		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+	if len(body) > 0 || filePath == "" {
+		if err := json.Unmarshal(body, &request); err != nil {
+			http.Error(w, "Error parsing request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	options := project.ScriptOptions{
+		BlockHeight:    request.BlockHeight,
+		Simplified:     request.Simplified,
+		NamedArguments: request.NamedArguments,
+	}
+
+	var result *project.ScriptResult
+	if filePath != "" {
+		result, err = currentProject.ExecuteScriptFile(filePath, request.Arguments, options)
+	} else {
+		result, err = currentProject.ExecuteScript([]byte(request.Source), request.Location, request.Arguments, options)
+	}
+
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, git.ErrSymlink) {
+		writeFileError(w, err)
+		return
+	}
+
+	if errors.Is(err, project.ErrBlockNotFound) {
+		writeError(w, http.StatusNotFound, "block_not_found", err)
 		return
 	}
 
-	result, err := currentProject.ExecuteScript([]byte(request.Source), request.Location, request.Arguments)
+	if errors.Is(err, project.ErrInvalidArguments) {
+		writeError(w, http.StatusBadRequest, "invalid_arguments", err)
+		return
+	}
+
+	if errors.Is(err, project.ErrUnresolvedImports) {
+		writeError(w, http.StatusUnprocessableEntity, "unresolved_imports", err)
+		return
+	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeProjectError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_, err = w.Write(result)
+	writeJson(w, http.StatusCreated, result)
+}
+
+// parametersHandler returns the parameters of the script or transaction from the request body,
+// or of the repository file if the request path continues with a file path.
+func parametersHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
+	var parameters *project.ProgramParameters
+	var err error
+
+	switch {
+	case r.Method == "GET" && filePath != "":
+		parameters, err = currentProject.FileParameters(filePath)
+	case r.Method == "POST" && filePath == "":
+		var request ParametersRequest
+		if err := readJson(r, &request); err != nil {
+			http.Error(w, "Error parsing request body", http.StatusBadRequest)
+			return
+		}
+		parameters, err = currentProject.Parameters([]byte(request.Source), request.Location)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, git.ErrSymlink) {
+		writeFileError(w, err)
+		return
+	}
+
+	var cadenceError *project.CadenceError
+	if errors.Is(err, project.ErrInvalidArguments) || errors.As(err, &cadenceError) {
+		writeError(w, http.StatusBadRequest, "invalid_program", err)
+		return
+	}
 
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to write response")
+		writeProjectError(w, err)
+		return
 	}
+
+	writeJson(w, http.StatusOK, parameters)
+}
+
+type ParametersRequest struct {
+	Source   string `json:"source"`
+	Location string `json:"location"`
 }
 
-func blockchainStateHandler(w http.ResponseWriter, r *http.Request) {
+func blockchainStateHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
 	switch r.Method {
 	case "GET":
-		getBlockchainState(w, r)
+		getBlockchainState(w, r, currentProject)
 	default:
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	}
 }
 
-func getBlockchainState(w http.ResponseWriter, r *http.Request) {
-	if currentProject == nil {
-		http.Error(w, "Project not created", http.StatusBadRequest)
-		return
-	}
-
+func getBlockchainState(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
 	jsonState, err := currentProject.BlockchainState()
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeProjectError(w, err)
 		return
 	}
 
@@ -164,170 +425,1335 @@ func getBlockchainState(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(jsonState)
 
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to write response")
+		logger.Error().Err(err).Msg("Failed to write response")
 	}
 }
 
-func projectLogsHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case "GET":
-		listProjectLogs(w, r)
-	default:
+// chainHandler routes requests of the form /chain/{collection} and /chain/{collection}/{id},
+// where collection is blocks, collections, transactions, transaction-results or events.
+func chainHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, subPath string) {
+	if r.Method != "GET" {
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
 	}
-}
 
-func listProjectLogs(w http.ResponseWriter, r *http.Request) {
-	if currentProject == nil {
-		http.Error(w, "Project not created", http.StatusBadRequest)
+	if subPath == "stream" {
+		streamChainUpdates(w, r, currentProject)
 		return
 	}
 
-	jsonLogs, err := logCache.LogsJson()
+	collection, id, _ := strings.Cut(subPath, "/")
+
+	var result any
+	var err error
+
+	if id == "" {
+		query, queryErr := chainQuery(r)
+		if queryErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_query", queryErr)
+			return
+		}
+
+		switch collection {
+		case "blocks":
+			result, err = currentProject.Blocks(query)
+		case "collections":
+			result, err = currentProject.Collections(query)
+		case "transactions":
+			result, err = currentProject.Transactions(query)
+		case "transaction-results":
+			result, err = currentProject.TransactionResults(query)
+		case "events":
+			result, err = currentProject.Events(query)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+	} else {
+		switch collection {
+		case "blocks":
+			result, err = currentProject.Block(id)
+		case "collections":
+			result, err = currentProject.Collection(id)
+		case "transactions":
+			result, err = currentProject.Transaction(id)
+		case "transaction-results":
+			result, err = currentProject.TransactionResult(id)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeChainError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	writeJson(w, http.StatusOK, result)
+}
 
-	_, err = w.Write(jsonLogs)
+// chainQuery reads the filters and pagination of a chain collection request from the URL query.
+func chainQuery(r *http.Request) (project.ChainQuery, error) {
+	values := r.URL.Query()
 
-	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to write response")
+	query := project.ChainQuery{
+		EventType:     values.Get("type"),
+		Status:        project.TransactionStatus(values.Get("status")),
+		Address:       values.Get("address"),
+		TransactionID: values.Get("transactionId"),
 	}
-}
 
-func projectFilesHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case "GET":
-		listProjectFilesHandler(w, r)
-	default:
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	for name, target := range map[string]*int{"offset": &query.Offset, "limit": &query.Limit} {
+		if param := values.Get(name); param != "" {
+			value, err := strconv.Atoi(param)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s parameter", name)
+			}
+			*target = value
+		}
 	}
+
+	for name, target := range map[string]**uint64{"startHeight": &query.StartHeight, "endHeight": &query.EndHeight} {
+		if param := values.Get(name); param != "" {
+			value, err := strconv.ParseUint(param, 10, 64)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s parameter", name)
+			}
+			*target = &value
+		}
+	}
+
+	return query, nil
 }
 
-func listProjectFilesHandler(w http.ResponseWriter, r *http.Request) {
-	if currentProject == nil {
-		http.Error(w, "Project not created", http.StatusBadRequest)
+// streamChainUpdates sends committed blocks as Server-Sent Events, each followed by its transaction results and events
+// matching the query parameters, as "block", "transaction-result" and "event" events.
+// New blocks are sent, unless the startHeight query parameter is given.
+// The last event of each block has the block height as ID, so clients can resume after the last received block
+// with the Last-Event-ID header (which browsers send when reconnecting).
+func streamChainUpdates(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
 		return
 	}
 
-	files, err := currentProject.Files()
-
+	query, err := chainQuery(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, "invalid_query", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	jsonFiles, err := json.Marshal(files)
+	height := query.StartHeight
+
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		lastHeight, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID header", http.StatusBadRequest)
+			return
+		}
+		nextHeight := lastHeight + 1
+		height = &nextHeight
+	}
 
+	// Validate the query before the response is started, so that errors can still be returned.
+	updates, nextHeight, committed, err := currentProject.ChainUpdates(height, query)
 	if err != nil {
-		logger.Fatal().Err(err)
+		writeChainError(w, err)
+		return
 	}
 
-	_, err = w.Write(jsonFiles)
+	unsubscribe := currentProject.Subscribe()
+	defer unsubscribe()
 
-	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to write response")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		for _, update := range updates {
+			err := writeChainUpdate(w, update)
+			if err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+
+		// Updates are returned in batches, the next batch is sent right away when catching up.
+		// The stream ends when the project is closed, e.g. because it was deleted.
+		if len(updates) == 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-currentProject.Closed():
+				return
+			case <-committed:
+			case <-keepAlive.C:
+				_, err := fmt.Fprint(w, ": keep-alive\n\n")
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		updates, nextHeight, committed, err = currentProject.ChainUpdates(&nextHeight, query)
+		if err != nil {
+			return
+		}
 	}
 }
 
-func projectsHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case "POST":
-		createProjectHandler(w, r)
+// writeChainUpdate writes the events of a block, the last one has the block height as ID.
+func writeChainUpdate(w http.ResponseWriter, update project.ChainUpdate) error {
+	type serverSentEvent struct {
+		name string
+		data any
+	}
+
+	events := []serverSentEvent{{name: "block", data: update.Block}}
+	for _, result := range update.TransactionResults {
+		events = append(events, serverSentEvent{name: "transaction-result", data: result})
+	}
+	for _, event := range update.Events {
+		events = append(events, serverSentEvent{name: "event", data: event})
+	}
+
+	for i, event := range events {
+		data, err := json.Marshal(event.data)
+		if err != nil {
+			return err
+		}
+
+		if i == len(events)-1 {
+			_, err = fmt.Fprintf(w, "id: %d\n", update.Block.Height)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeChainError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, project.ErrInvalidChainQuery):
+		writeError(w, http.StatusBadRequest, "invalid_query", err)
+	case errors.Is(err, project.ErrBlockNotFound):
+		writeError(w, http.StatusNotFound, "block_not_found", err)
+	case errors.Is(err, project.ErrCollectionNotFound):
+		writeError(w, http.StatusNotFound, "collection_not_found", err)
+	case errors.Is(err, project.ErrTransactionNotFound):
+		writeError(w, http.StatusNotFound, "transaction_not_found", err)
+	default:
+		writeProjectError(w, err)
+	}
+}
+
+// accountsHandler routes requests of the form /accounts, /accounts/{address}, /accounts/{address}/fund,
+// /accounts/{address}/keys/{index}, /accounts/{address}/storage and /accounts/{address}/storage/{domain}/{identifier}.
+func accountsHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, subPath string) {
+	address, resource, _ := strings.Cut(subPath, "/")
+	resource, path, _ := strings.Cut(resource, "/")
+
+	switch {
+	case address == "" && r.Method == "GET":
+		result, err := currentProject.Accounts()
+		writeAccountResult(w, http.StatusOK, result, err)
+	case address == "" && r.Method == "POST":
+		createAccountHandler(w, r, currentProject)
+	case resource == "" && r.Method == "GET":
+		result, err := currentProject.Account(address)
+		writeAccountResult(w, http.StatusOK, result, err)
+	case resource == "fund" && path == "" && r.Method == "POST":
+		fundAccountHandler(w, r, currentProject, address)
+	case resource == "keys" && path == "" && r.Method == "POST":
+		addAccountKeyHandler(w, r, currentProject, address)
+	case resource == "keys" && path != "" && r.Method == "DELETE":
+		revokeAccountKeyHandler(w, r, currentProject, address, path)
+	case resource == "storage" && path == "" && r.Method == "GET":
+		result, err := currentProject.AccountStorage(address)
+		writeAccountResult(w, http.StatusOK, result, err)
+	case resource == "storage" && r.Method == "GET":
+		result, err := currentProject.AccountStorageValue(address, path, r.URL.Query().Get("simplified") == "true")
+		writeAccountResult(w, http.StatusOK, result, err)
+	case resource != "" && resource != "fund" && resource != "keys" && resource != "storage":
+		http.NotFound(w, r)
 	default:
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	}
 }
 
-type CreateProjectRequest struct {
-	ProjectUrl string `json:"projectUrl"`
+type CreateAccountRequest struct {
+	Name string `json:"name"`
+	// Hex encoded private key, a key is generated if empty.
+	PrivateKey string `json:"privateKey"`
+	// Key algorithms, defaults to ECDSA_P256 and SHA3_256.
+	SigAlgo  string `json:"sigAlgo"`
+	HashAlgo string `json:"hashAlgo"`
+	// Amount of FLOW transferred from the service account.
+	Balance string `json:"balance"`
+	// Add the account to flow.json in the repository.
+	Save bool `json:"save"`
 }
 
-func createProjectHandler(w http.ResponseWriter, r *http.Request) {
-	currentProject = project.New(logger)
+func createAccountHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	var request CreateAccountRequest
+	if err := readJson(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+	account, err := currentProject.CreateAccount(project.CreateAccountOptions{
+		Name:       request.Name,
+		PrivateKey: request.PrivateKey,
+		SigAlgo:    request.SigAlgo,
+		HashAlgo:   request.HashAlgo,
+		Balance:    request.Balance,
+		Save:       request.Save,
+	})
+
+	writeAccountResult(w, http.StatusCreated, account, err)
+}
+
+type FundAccountRequest struct {
+	// Amount of FLOW transferred from the service account, e.g. "10.0".
+	Amount string `json:"amount"`
+}
+
+func fundAccountHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, address string) {
+	var request FundAccountRequest
+	if err := readJson(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err)
 		return
 	}
 
-	var request CreateProjectRequest
-	if err := json.Unmarshal(body, &request); err != nil {
-		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+	account, err := currentProject.FundAccount(address, request.Amount)
+	writeAccountResult(w, http.StatusOK, account, err)
+}
+
+type AddAccountKeyRequest struct {
+	// Hex encoded public key.
+	PublicKey string `json:"publicKey"`
+	// Key algorithms, defaults to ECDSA_P256 and SHA3_256.
+	SigAlgo  string `json:"sigAlgo"`
+	HashAlgo string `json:"hashAlgo"`
+	// Key weight, defaults to full weight (1000).
+	Weight int `json:"weight"`
+}
+
+func addAccountKeyHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, address string) {
+	var request AddAccountKeyRequest
+	if err := readJson(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err)
 		return
 	}
 
-	err = currentProject.Open(request.ProjectUrl)
+	account, err := currentProject.AddAccountKey(address, project.AccountKeyOptions{
+		PublicKey: request.PublicKey,
+		SigAlgo:   request.SigAlgo,
+		HashAlgo:  request.HashAlgo,
+		Weight:    request.Weight,
+	})
 
-	if err := json.Unmarshal(body, &request); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	writeAccountResult(w, http.StatusOK, account, err)
+}
+
+func revokeAccountKeyHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, address string, keyIndex string) {
+	index, err := strconv.Atoi(keyIndex)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Errorf("invalid key index %s", keyIndex))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	account, err := currentProject.RevokeAccountKey(address, index)
+	writeAccountResult(w, http.StatusOK, account, err)
 }
 
-func initLogger() (*zerolog.Logger, *CacheLogWriter) {
+// writeAccountResult writes the result of an account operation, or its error.
+func writeAccountResult(w http.ResponseWriter, status int, result any, err error) {
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
 
-	level := zerolog.InfoLevel
-	zerolog.MessageFieldName = "msg"
+	writeJson(w, status, result)
+}
 
-	cacheWriter := NewCacheLogWriter()
+func writeAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, project.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, "account_not_found", err)
+	case errors.Is(err, project.ErrStorageValueNotFound):
+		writeError(w, http.StatusNotFound, "storage_value_not_found", err)
+	case errors.Is(err, project.ErrInvalidAddress):
+		writeError(w, http.StatusBadRequest, "invalid_address", err)
+	case errors.Is(err, project.ErrInvalidPath):
+		writeError(w, http.StatusBadRequest, "invalid_path", err)
+	case errors.Is(err, project.ErrInvalidAccount):
+		writeError(w, http.StatusBadRequest, "invalid_account", err)
+	case errors.Is(err, project.ErrAccountExists):
+		writeError(w, http.StatusConflict, "account_exists", err)
+	case errors.Is(err, project.ErrAccountNotSignable):
+		writeError(w, http.StatusUnprocessableEntity, "account_not_signable", err)
+	default:
+		writeProjectError(w, err)
+	}
+}
 
-	writer := zerolog.MultiLevelWriter(
-		NewTextWriter(),
-		cacheWriter,
-	)
+func projectLogsHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, subPath string) {
+	switch {
+	case subPath == "" && r.Method == "GET":
+		listProjectLogs(w, r, currentProject)
+	case subPath == "stream" && r.Method == "GET":
+		streamProjectLogs(w, r, currentProject)
+	case subPath != "" && subPath != "stream":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
 
-	logger := zerolog.New(writer).With().Timestamp().Logger().Level(level)
+func listProjectLogs(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	query, err := logQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_query", err)
+		return
+	}
 
-	return &logger, cacheWriter
+	logs, err := currentProject.Logs(query)
+	if err != nil {
+		writeLogError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, logs)
 }
 
-func NewTextWriter() zerolog.ConsoleWriter {
-	writer := zerolog.ConsoleWriter{Out: os.Stdout}
-	writer.FormatMessage = func(i interface{}) string {
-		if i == nil {
-			return ""
+// logQuery reads the log filters from the query parameters, times are in RFC 3339 format.
+func logQuery(r *http.Request) (project.LogQuery, error) {
+	values := r.URL.Query()
+
+	query := project.LogQuery{
+		Level:  values.Get("level"),
+		Source: project.LogSource(values.Get("source")),
+		Search: values.Get("search"),
+	}
+
+	if param := values.Get("limit"); param != "" {
+		limit, err := strconv.Atoi(param)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit parameter")
 		}
-		return fmt.Sprintf("%-44s", i)
+		query.Limit = limit
 	}
 
-	return writer
-}
+	if param := values.Get("after"); param != "" {
+		after, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid after parameter")
+		}
+		query.After = &after
+	}
+
+	for name, target := range map[string]**time.Time{"since": &query.Since, "until": &query.Until} {
+		if param := values.Get(name); param != "" {
+			value, err := time.Parse(time.RFC3339, param)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s parameter", name)
+			}
+			*target = &value
+		}
+	}
 
-type CacheLogWriter struct {
-	logs []string
+	return query, nil
 }
 
-func NewCacheLogWriter() *CacheLogWriter {
-	return &CacheLogWriter{
-		logs: make([]string, 0),
+func writeLogError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, project.ErrInvalidLogQuery):
+		writeError(w, http.StatusBadRequest, "invalid_query", err)
+	default:
+		writeProjectError(w, err)
 	}
 }
 
-var _ io.Writer = &CacheLogWriter{}
+// streamKeepAlive is the interval of comments sent on idle event streams,
+// which prevents proxies from closing the connection.
+const streamKeepAlive = 15 * time.Second
+
+// streamProjectLogs sends log entries matching the query parameters as Server-Sent Events, starting with the kept ones.
+// The event ID is the ID of the entry, so clients can resume after the last received entry
+// with the Last-Event-ID header (which browsers send when reconnecting) or the offset query parameter.
+func streamProjectLogs(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
 
-func (c *CacheLogWriter) Write(p []byte) (n int, err error) {
-	c.logs = append(c.logs, string(p))
-	return len(p), nil
-}
+	query, err := logQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_query", err)
+		return
+	}
+
+	var offset uint64
 
-func (c *CacheLogWriter) LogsJson() ([]byte, error) {
-	res, err := json.Marshal(&c.logs)
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		lastOffset, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID header", http.StatusBadRequest)
+			return
+		}
+		offset = lastOffset + 1
+	} else if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
+		offset, err = strconv.ParseUint(offsetParam, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+			return
+		}
+	}
 
+	// Validate the query before the response is started, so that errors can still be returned.
+	logs, offset, changed, err := currentProject.LogsSince(offset, query)
 	if err != nil {
-		return nil, err
+		writeLogError(w, err)
+		return
 	}
 
-	return res, nil
+	unsubscribe := currentProject.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		for _, log := range logs {
+			data, err := json.Marshal(log)
+			if err != nil {
+				return
+			}
+
+			_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", log.ID, data)
+			if err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+
+		// The stream ends when the project is closed, e.g. because it was deleted.
+		select {
+		case <-r.Context().Done():
+			return
+		case <-currentProject.Closed():
+			return
+		case <-changed:
+		case <-keepAlive.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+		}
+
+		logs, offset, changed, err = currentProject.LogsSince(offset, query)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func projectFilesHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
+	if filePath == "" {
+		switch r.Method {
+		case "GET":
+			listProjectFilesHandler(w, r, currentProject)
+		default:
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		getProjectFileHandler(w, r, currentProject, filePath)
+	case "PUT":
+		putProjectFileHandler(w, r, currentProject, filePath)
+	case "PATCH":
+		patchProjectFileHandler(w, r, currentProject, filePath)
+	case "DELETE":
+		deleteProjectFileHandler(w, r, currentProject, filePath)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// getProjectFileHandler returns the file from the working tree,
+// or from the given commit, branch or tag if the revision query parameter is set.
+func getProjectFileHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
+	var file *git.RepositoryFile
+	var err error
+
+	if revision := r.URL.Query().Get("revision"); revision != "" {
+		file, err = currentProject.FileAtRevision(revision, filePath)
+	} else {
+		file, err = currentProject.File(filePath)
+	}
+
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, file)
+}
+
+type PutFileRequest struct {
+	Content     string `json:"content"`
+	IsDirectory bool   `json:"isDirectory"`
+}
+
+// putProjectFileHandler creates or overwrites a file, or creates a directory if requested.
+func putProjectFileHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
+	var request PutFileRequest
+	if err := readJson(r, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if request.IsDirectory {
+		err = currentProject.CreateDirectory(filePath)
+	} else {
+		err = currentProject.WriteFile(filePath, []byte(request.Content))
+	}
+
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+
+	getProjectFileHandler(w, r, currentProject, filePath)
+}
+
+type PatchFileRequest struct {
+	Path string `json:"path"`
+}
+
+// patchProjectFileHandler renames (moves) a file or directory to the path given in the request body.
+func patchProjectFileHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
+	var request PatchFileRequest
+	if err := readJson(r, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if request.Path == "" {
+		http.Error(w, "Missing new file path", http.StatusBadRequest)
+		return
+	}
+
+	err := currentProject.RenameFile(filePath, request.Path)
+
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+
+	getProjectFileHandler(w, r, currentProject, request.Path)
+}
+
+func deleteProjectFileHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, filePath string) {
+	err := currentProject.RemoveFile(filePath)
+
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeFileError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, project.ErrProjectClosed):
+		writeProjectError(w, err)
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, git.ErrRevisionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, fs.ErrExist):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, git.ErrSymlink):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, git.ErrMoveIntoItself):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func listProjectFilesHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	files, err := currentProject.Files()
+
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, files)
+}
+
+func deployHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	switch r.Method {
+	case "GET":
+		getDeploymentHandler(w, r, currentProject)
+	case "POST":
+		createDeploymentHandler(w, r, currentProject)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func getDeploymentHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	deployment, err := currentProject.LastDeployment()
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	if deployment == nil {
+		http.Error(w, "No contracts deployed yet", http.StatusNotFound)
+		return
+	}
+
+	writeJson(w, http.StatusOK, deployment)
+}
+
+type CreateDeploymentRequest struct {
+	// Changed files, only contracts from those files and their dependents are deployed.
+	// All contracts are deployed if empty.
+	Paths []string `json:"paths"`
+}
+
+func createDeploymentHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	var request CreateDeploymentRequest
+	if r.ContentLength != 0 {
+		if err := readJson(r, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	deployment, err := currentProject.Deploy(request.Paths...)
+
+	if errors.Is(err, project.ErrProjectClosed) {
+		writeProjectError(w, err)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	writeJson(w, http.StatusOK, deployment)
+}
+
+// snapshotsHandler routes requests of the form /snapshots, /snapshots/{name} and /snapshots/{name}/revert.
+func snapshotsHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, subPath string) {
+	name, action, _ := strings.Cut(subPath, "/")
+
+	switch {
+	case name == "" && r.Method == "GET":
+		snapshots, err := currentProject.Snapshots()
+		if err != nil {
+			writeSnapshotError(w, err)
+			return
+		}
+
+		writeJson(w, http.StatusOK, snapshots)
+	case name == "" && r.Method == "POST":
+		createSnapshotHandler(w, r, currentProject)
+	case name != "" && action == "" && r.Method == "DELETE":
+		deleteSnapshotHandler(w, r, currentProject, name)
+	case name != "" && action == "revert" && r.Method == "POST":
+		revertSnapshotHandler(w, r, currentProject, name)
+	case name != "" && action != "" && action != "revert":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+type CreateSnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+func createSnapshotHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	var request CreateSnapshotRequest
+	if err := readJson(r, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := currentProject.CreateSnapshot(request.Name)
+
+	if err != nil {
+		writeSnapshotError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusCreated, snapshot)
+}
+
+func revertSnapshotHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, name string) {
+	snapshot, err := currentProject.RevertSnapshot(name)
+
+	if err != nil {
+		writeSnapshotError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, snapshot)
+}
+
+func deleteSnapshotHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project, name string) {
+	err := currentProject.DeleteSnapshot(name)
+
+	if err != nil {
+		writeSnapshotError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeSnapshotError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, project.ErrSnapshotNotFound):
+		writeError(w, http.StatusNotFound, "snapshot_not_found", err)
+	case errors.Is(err, project.ErrSnapshotExists):
+		writeError(w, http.StatusConflict, "snapshot_exists", err)
+	case errors.Is(err, project.ErrInvalidSnapshotName):
+		writeError(w, http.StatusBadRequest, "invalid_snapshot_name", err)
+	default:
+		writeProjectError(w, err)
+	}
+}
+
+func statusHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	switch r.Method {
+	case "GET":
+		getStatusHandler(w, r, currentProject)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func getStatusHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	status, err := currentProject.Status()
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, status)
+}
+
+func diffHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	switch r.Method {
+	case "GET":
+		getDiffHandler(w, r, currentProject)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// getDiffHandler returns diffs for files given with (repeated) path query parameters,
+// or for all changed files if none are given.
+func getDiffHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	diffs, err := currentProject.Diff(r.URL.Query()["path"]...)
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, diffs)
+}
+
+func commitsHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	switch r.Method {
+	case "GET":
+		listCommitsHandler(w, r, currentProject)
+	case "POST":
+		createCommitHandler(w, r, currentProject)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func listCommitsHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	limit := 0
+
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+
+		if err != nil {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	commits, err := currentProject.Log(limit, r.URL.Query().Get("path"))
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, commits)
+}
+
+type CommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type CreateCommitRequest struct {
+	Message string       `json:"message"`
+	Author  CommitAuthor `json:"author"`
+	// Paths to include in the commit, all changes are committed if empty.
+	Paths []string `json:"paths"`
+	// Push the new commit to the origin repository using the provided credentials.
+	Push        bool            `json:"push"`
+	Credentials git.Credentials `json:"credentials"`
+}
+
+type CreateCommitResponse struct {
+	Hash   string `json:"hash"`
+	Pushed bool   `json:"pushed"`
+}
+
+func createCommitHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	var request CreateCommitRequest
+	if err := readJson(r, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if request.Message == "" || request.Author.Name == "" || request.Author.Email == "" {
+		http.Error(w, "Commit message, author name and email are required", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := currentProject.Commit(git.CommitOptions{
+		AuthorName:  request.Author.Name,
+		AuthorEmail: request.Author.Email,
+		Message:     request.Message,
+		Paths:       request.Paths,
+	})
+
+	if errors.Is(err, git.ErrNothingToCommit) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+
+	pushed := false
+
+	if request.Push {
+		pushed, err = currentProject.Push(request.Credentials)
+
+		if err != nil {
+			writePushError(w, fmt.Errorf("created commit %s, but push failed: %w", hash, err))
+			return
+		}
+	}
+
+	writeJson(w, http.StatusCreated, CreateCommitResponse{
+		Hash:   hash,
+		Pushed: pushed,
+	})
+}
+
+func writePushError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, project.ErrProjectClosed):
+		writeProjectError(w, err)
+	case errors.Is(err, git.ErrDetachedHead):
+		writeError(w, http.StatusConflict, "detached_head", err)
+	case errors.Is(err, git.ErrPushRejected):
+		writeError(w, http.StatusConflict, "push_rejected", err)
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		writeError(w, http.StatusForbidden, "repository_access_denied", err)
+	default:
+		writeError(w, http.StatusBadGateway, "push_failed", err)
+	}
+}
+
+func pushHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	switch r.Method {
+	case "POST":
+		createPushHandler(w, r, currentProject)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+type PushRequest struct {
+	Credentials git.Credentials `json:"credentials"`
+}
+
+func createPushHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	var request PushRequest
+	if err := readJson(r, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err := currentProject.Push(request.Credentials)
+
+	if err != nil {
+		writePushError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// exportHandler downloads the project bundle, which can be imported as a new project.
+func exportHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	if r.Method != "GET" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The bundle is buffered, so that export failures can still be reported with an error status.
+	var bundle bytes.Buffer
+	err := currentProject.Export(&bundle)
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"project-%s.tar.gz\"", currentProject.ID()))
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(bundle.Bytes())
+
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to write response")
+	}
+}
+
+// maxImportSize is the maximum size of an uploaded (compressed) project bundle.
+const maxImportSize = 256 << 20
+
+// importProjectHandler creates a project from a bundle uploaded as the request body.
+func importProjectHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	p, err := projects.Import(http.MaxBytesReader(w, r.Body, maxImportSize))
+
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesError):
+		writeError(w, http.StatusRequestEntityTooLarge, "bundle_too_large", err)
+	case errors.Is(err, project.ErrInvalidBundle):
+		writeError(w, http.StatusBadRequest, "invalid_bundle", err)
+	case err != nil:
+		writeProjectError(w, err)
+	default:
+		writeProjectInfo(w, http.StatusCreated, p)
+	}
+}
+
+func projectsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		listProjectsHandler(w, r)
+	case "POST":
+		createProjectHandler(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+type CreateProjectRequest struct {
+	ProjectUrl string `json:"projectUrl"`
+	// Branch, tag or commit SHA to check out, defaults to the default branch.
+	Ref string `json:"ref"`
+	// Directory containing flow.json, relative to the repository root.
+	Directory string `json:"directory"`
+	// Number of commits to clone, full history is cloned if zero.
+	Depth int `json:"depth"`
+	// Redeploy affected contracts whenever project files change.
+	AutoDeploy bool `json:"autoDeploy"`
+	// Charge transaction fees and enforce storage limits, as on mainnet.
+	TransactionFees bool `json:"transactionFees"`
+	StorageLimit    bool `json:"storageLimit"`
+	// Serve the project chain through the Flow Access gRPC and REST APIs, on allocated local ports.
+	AccessAPI bool `json:"accessApi"`
+}
+
+func listProjectsHandler(w http.ResponseWriter, r *http.Request) {
+	projectInfos := make([]project.ProjectInfo, 0)
+	for _, p := range projects.List() {
+		// Projects closed since they were listed (e.g. because they were deleted) are left out.
+		info, err := p.Info()
+		if err != nil {
+			continue
+		}
+
+		projectInfos = append(projectInfos, info)
+	}
+
+	jsonProjects, err := json.Marshal(projectInfos)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonProjects)
+
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to write response")
+	}
+}
+
+func createProjectHandler(w http.ResponseWriter, r *http.Request) {
+	var request CreateProjectRequest
+	if err := readJson(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	if request.ProjectUrl == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", errors.New("missing project URL"))
+		return
+	}
+
+	if request.Depth < 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", errors.New("depth must not be negative"))
+		return
+	}
+
+	currentProject, err := projects.Create(project.OpenOptions{
+		ProjectUrl:      request.ProjectUrl,
+		Ref:             request.Ref,
+		Directory:       request.Directory,
+		Depth:           request.Depth,
+		AutoDeploy:      request.AutoDeploy,
+		TransactionFees: request.TransactionFees,
+		StorageLimit:    request.StorageLimit,
+		AccessAPI:       request.AccessAPI,
+	})
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeProjectDeployment(w, http.StatusCreated, currentProject)
+}
+
+// ProjectDeploymentResponse holds the project details and the results of the deployment of its contracts,
+// which can include contracts that failed to deploy and can be fixed in the project.
+type ProjectDeploymentResponse struct {
+	project.ProjectInfo
+	Deployment *project.Deployment `json:"deployment"`
+}
+
+func writeProjectDeployment(w http.ResponseWriter, status int, currentProject *project.Project) {
+	info, err := currentProject.Info()
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	deployment, err := currentProject.LastDeployment()
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeJson(w, status, ProjectDeploymentResponse{
+		ProjectInfo: info,
+		Deployment:  deployment,
+	})
+}
+
+type CheckoutRequest struct {
+	Ref string `json:"ref"`
+	// Discard uncommitted changes in the working tree.
+	Force bool `json:"force"`
+}
+
+func checkoutHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	switch r.Method {
+	case "POST":
+		createCheckoutHandler(w, r, currentProject)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func createCheckoutHandler(w http.ResponseWriter, r *http.Request, currentProject *project.Project) {
+	var request CheckoutRequest
+	if err := readJson(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	if request.Ref == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", errors.New("missing ref"))
+		return
+	}
+
+	err := currentProject.Checkout(request.Ref, request.Force)
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeProjectDeployment(w, http.StatusOK, currentProject)
+}
+
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// CadenceError locates the error in the source code, if the error was reported by Cadence.
+	CadenceError *project.CadenceError `json:"cadenceError,omitempty"`
+	// Arguments lists the arguments that couldn't be converted to their parameter types.
+	Arguments []project.ArgumentError `json:"arguments,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, status int, code string, err error) {
+	response := ErrorResponse{
+		Code:    code,
+		Message: err.Error(),
+	}
+
+	var cadenceError *project.CadenceError
+	if errors.As(err, &cadenceError) {
+		response.CadenceError = cadenceError
+	}
+
+	var argumentsError *project.ArgumentsError
+	if errors.As(err, &argumentsError) {
+		response.Arguments = argumentsError.Errors
+	}
+
+	writeJson(w, status, response)
+}
+
+// writeProjectError maps errors from opening or checking out a project to structured error responses.
+func writeProjectError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, project.ErrProjectClosed):
+		writeError(w, http.StatusGone, "project_closed", err)
+	case errors.Is(err, git.ErrReferenceNotFound):
+		writeError(w, http.StatusNotFound, "reference_not_found", err)
+	case errors.Is(err, git.ErrUncommittedChanges):
+		writeError(w, http.StatusConflict, "uncommitted_changes", err)
+	case errors.Is(err, project.ErrConfigNotFound):
+		writeError(w, http.StatusUnprocessableEntity, "config_not_found", err)
+	case errors.Is(err, transport.ErrRepositoryNotFound), errors.Is(err, transport.ErrEmptyRemoteRepository):
+		writeError(w, http.StatusNotFound, "repository_not_found", err)
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		writeError(w, http.StatusForbidden, "repository_access_denied", err)
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", err)
+	}
+}
+
+func readJson(r *http.Request, value any) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("error reading request body: %w", err)
+	}
+
+	if err := json.Unmarshal(body, value); err != nil {
+		return fmt.Errorf("error parsing request body: %w", err)
+	}
+
+	return nil
+}
+
+func writeJson(w http.ResponseWriter, status int, value any) {
+	jsonValue, err := json.Marshal(value)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(jsonValue)
+
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to write response")
+	}
+}
+
+// initLogger returns the server logger and the output it writes to, which is shared with the project loggers.
+func initLogger() (*zerolog.Logger, io.Writer) {
+
+	level := zerolog.InfoLevel
+	zerolog.MessageFieldName = "msg"
+
+	writer := NewTextWriter()
+
+	logger := zerolog.New(writer).With().Timestamp().Logger().Level(level)
+
+	return &logger, writer
+}
+
+func NewTextWriter() zerolog.ConsoleWriter {
+	writer := zerolog.ConsoleWriter{Out: os.Stdout}
+	writer.FormatMessage = func(i interface{}) string {
+		if i == nil {
+			return ""
+		}
+		return fmt.Sprintf("%-44s", i)
+	}
+
+	return writer
 }
@@ -0,0 +1,169 @@
+package emulator
+
+import (
+	"errors"
+	"github.com/onflow/flow-emulator/adapters"
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-emulator/server/access"
+	"github.com/onflow/flow-go/engine/access/rest"
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/engine/access/state_stream/backend"
+	"github.com/onflow/flow-go/engine/access/subscription"
+	flowgo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"google.golang.org/grpc"
+	"net"
+	"net/http"
+)
+
+// accessAPIHost is the host the Access APIs listen on, they are only reachable locally.
+const accessAPIHost = "127.0.0.1"
+
+// AccessAPI serves the Flow Access gRPC and REST APIs of a blockchain,
+// so that Flow clients (e.g. FCL, flow-cli or the Go SDK) can use it like an access node.
+type AccessAPI struct {
+	// GRPCAddress and RESTAddress are the host and port of the APIs, which are allocated when they are started.
+	GRPCAddress string
+	RESTAddress string
+	grpcServer  *grpc.Server
+	restServer  *http.Server
+}
+
+// AccessTransactionHandler commits a transaction sent through the Access APIs by calling commit,
+// so that the owner of the blockchain can order it with its own operations on the chain.
+type AccessTransactionHandler func(commit func() error) error
+
+// accessEmulator commits each transaction sent through the Access API in its own block, as the gateway does,
+// since the blockchain doesn't commit blocks automatically.
+type accessEmulator struct {
+	*emulator.Blockchain
+	gateway *Gateway
+	handler AccessTransactionHandler
+}
+
+func (e *accessEmulator) SendTransaction(tx *flowgo.TransactionBody) error {
+	commit := func() error {
+		return e.gateway.commitTransaction(func() error {
+			return e.Blockchain.SendTransaction(tx)
+		})
+	}
+
+	if e.handler == nil {
+		return commit()
+	}
+
+	return e.handler(commit)
+}
+
+// SetAccessTransactionHandler sets the handler of transactions sent through the Access APIs,
+// which applies when the APIs are started.
+func (b *Blockchain) SetAccessTransactionHandler(handler AccessTransactionHandler) {
+	b.accessTransactionHandler = handler
+}
+
+// AccessAPI returns the running Access API of the blockchain, or nil if it isn't started.
+func (b *Blockchain) AccessAPI() *AccessAPI {
+	return b.accessAPI.Load()
+}
+
+// StartAccessAPI serves the Access APIs on local ports, the blockchain must be started.
+// Nothing is done if the APIs are already served.
+func (b *Blockchain) StartAccessAPI() error {
+	if b.emulator == nil {
+		return errors.New("blockchain is not started")
+	}
+
+	if b.accessAPI.Load() != nil {
+		return nil
+	}
+
+	grpcListener, err := net.Listen("tcp", net.JoinHostPort(accessAPIHost, "0"))
+	if err != nil {
+		return err
+	}
+
+	restListener, err := net.Listen("tcp", net.JoinHostPort(accessAPIHost, "0"))
+	if err != nil {
+		_ = grpcListener.Close()
+		return err
+	}
+
+	chain := b.emulator.GetChain()
+	adapter := adapters.NewAccessAdapter(b.logger, &accessEmulator{
+		Blockchain: b.emulator,
+		gateway:    b.gateway,
+		handler:    b.accessTransactionHandler,
+	})
+
+	grpcServer := access.NewGRPCServer(b.logger, adapter, chain, accessAPIHost, 0, false).Server()
+
+	// The REST server is created without metrics, since they are registered globally and can't be shared by projects.
+	restServer, err := rest.NewServer(
+		adapter,
+		rest.Config{
+			ListenAddress: restListener.Addr().String(),
+			WriteTimeout:  rest.DefaultWriteTimeout,
+			ReadTimeout:   rest.DefaultReadTimeout,
+			IdleTimeout:   rest.DefaultIdleTimeout,
+		},
+		*b.logger,
+		chain,
+		metrics.NewNoopCollector(),
+		access.NewStateStreamBackend(b.emulator, *b.logger),
+		backend.Config{
+			EventFilterConfig:    state_stream.DefaultEventFilterConfig,
+			MaxGlobalStreams:     subscription.DefaultMaxGlobalStreams,
+			ClientSendTimeout:    subscription.DefaultSendTimeout,
+			ClientSendBufferSize: subscription.DefaultSendBufferSize,
+			ResponseLimit:        subscription.DefaultResponseLimit,
+			HeartbeatInterval:    subscription.DefaultHeartbeatInterval,
+		},
+	)
+	if err != nil {
+		_ = grpcListener.Close()
+		_ = restListener.Close()
+		return err
+	}
+
+	go func() {
+		err := grpcServer.Serve(grpcListener)
+		if err != nil {
+			b.logger.Error().Err(err).Msg("Access gRPC API stopped")
+		}
+	}()
+
+	go func() {
+		err := restServer.Serve(restListener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			b.logger.Error().Err(err).Msg("Access REST API stopped")
+		}
+	}()
+
+	accessAPI := &AccessAPI{
+		GRPCAddress: grpcListener.Addr().String(),
+		RESTAddress: restListener.Addr().String(),
+		grpcServer:  grpcServer,
+		restServer:  restServer,
+	}
+	b.accessAPI.Store(accessAPI)
+
+	b.logger.Info().Msgf("Serving Access API on %s (gRPC) and %s (REST)", accessAPI.GRPCAddress, accessAPI.RESTAddress)
+
+	return nil
+}
+
+// StopAccessAPI stops serving the Access APIs, open connections (e.g. subscriptions) are closed.
+// Requests in progress aren't waited for, since they may wait for the access transaction handler
+// while its owner stops the APIs.
+func (b *Blockchain) StopAccessAPI() {
+	accessAPI := b.accessAPI.Load()
+	if accessAPI == nil {
+		return
+	}
+
+	accessAPI.grpcServer.Stop()
+	_ = accessAPI.restServer.Close()
+	b.accessAPI.Store(nil)
+
+	b.logger.Info().Msg("Stopped Access API")
+}
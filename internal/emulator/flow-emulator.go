@@ -1,40 +1,56 @@
 package emulator
 
 import (
+	"context"
 	"fri-flowser-playground/internal/emulator/store"
 	"github.com/onflow/flow-emulator/emulator"
-	"github.com/onflow/flowkit"
+	"github.com/onflow/flow-emulator/storage"
+	"github.com/onflow/flow-emulator/types"
+	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flowkit/gateway"
 	"github.com/rs/zerolog"
+	"io"
+	"sync/atomic"
 )
 
 type Blockchain struct {
-	logger     *zerolog.Logger
-	store      *store.InMemory
-	blockchain *emulator.Blockchain
-	flow       *flowkit.Flowkit
-	gateway    *gateway.EmulatorGateway
+	logger   *zerolog.Logger
+	store    *store.InMemory
+	emulator *emulator.Blockchain
+	gateway  *Gateway
+	// accessAPI is nil unless the Access APIs are served,
+	// it's read atomically so that projects can check it without waiting for other operations.
+	accessAPI atomic.Pointer[AccessAPI]
+	// accessTransactionHandler commits the transactions sent through the Access APIs, if set.
+	accessTransactionHandler AccessTransactionHandler
+	// stopped is closed when the blockchain is stopped, which ends subscriptions to its blocks.
+	stopped chan struct{}
+}
+
+type Options struct {
+	// TransactionFees charges the payer for transaction execution, as on mainnet.
+	TransactionFees bool
+	// StorageLimit rejects transactions that store more data than the accounts' FLOW balance allows.
+	StorageLimit bool
+	// AccessAPI serves the Flow Access gRPC and REST APIs on local ports.
+	AccessAPI bool
 }
 
 func New(logger *zerolog.Logger) *Blockchain {
-	s := store.New()
 	return &Blockchain{
-		store:  s,
-		logger: logger,
-		gateway: gateway.NewEmulatorGatewayWithOpts(
-			&gateway.EmulatorKey{
-				PublicKey: emulator.DefaultServiceKey().AccountKey().PublicKey,
-				SigAlgo:   emulator.DefaultServiceKeySigAlgo,
-				HashAlgo:  emulator.DefaultServiceKeyHashAlgo,
-			},
-			gateway.WithEmulatorOptions(
-				emulator.WithLogger(*logger),
-				emulator.WithStore(s),
-				emulator.WithTransactionValidationEnabled(false),
-				emulator.WithStorageLimitEnabled(false),
-				emulator.WithTransactionFeesEnabled(false),
-			),
-		),
+		store:   store.New(),
+		logger:  logger,
+		stopped: make(chan struct{}),
+	}
+}
+
+// NewPersistent returns a blockchain whose blocks are persisted to the given journal file
+// and restored from it when the blockchain is started.
+func NewPersistent(logger *zerolog.Logger, journalPath string) *Blockchain {
+	return &Blockchain{
+		store:   store.NewPersistent(journalPath),
+		logger:  logger,
+		stopped: make(chan struct{}),
 	}
 }
 
@@ -42,20 +58,118 @@ func (b *Blockchain) State() ([]byte, error) {
 	return b.store.Json()
 }
 
+// Storage returns the store holding the blocks, transactions and events of the blockchain.
+func (b *Blockchain) Storage() storage.Store {
+	return b.store
+}
+
+// ChainCounts returns the numbers of blocks, collections, transactions and events committed
+// up to and including the block height.
+func (b *Blockchain) ChainCounts(height uint64) store.ChainCounts {
+	return b.store.ChainCounts(height)
+}
+
 func (b *Blockchain) Gateway() gateway.Gateway {
 	return b.gateway
 }
 
-func (b *Blockchain) Start() error {
+// TransactionExecution returns the execution details of a transaction sent through the gateway.
+func (b *Blockchain) TransactionExecution(id flow.Identifier) (*types.TransactionResult, bool) {
+	return b.gateway.TransactionExecution(id)
+}
+
+// Start creates the emulator instance backed by the blockchain store.
+// Gateway must only be used after the blockchain is started.
+func (b *Blockchain) Start(options Options) error {
+	err := b.store.Start()
+
+	if err != nil {
+		return err
+	}
+
 	blockchain, err := emulator.New(
+		emulator.WithServicePublicKey(
+			emulator.DefaultServiceKey().AccountKey().PublicKey,
+			emulator.DefaultServiceKeySigAlgo,
+			emulator.DefaultServiceKeyHashAlgo,
+		),
 		emulator.WithLogger(*b.logger),
+		emulator.WithStore(b.store),
+		emulator.WithTransactionValidationEnabled(false),
+		emulator.WithStorageLimitEnabled(options.StorageLimit),
+		emulator.WithTransactionFeesEnabled(options.TransactionFees),
 	)
 
 	if err != nil {
 		return err
 	}
 
-	b.blockchain = blockchain
+	b.emulator = blockchain
+	b.gateway = newGateway(b.logger, blockchain)
+
+	if options.AccessAPI {
+		return b.StartAccessAPI()
+	}
 
 	return nil
 }
+
+// Stop releases the emulator instance and its store, and stops the Access APIs.
+// Subscribers waiting for committed blocks are notified through the Stopped channel.
+func (b *Blockchain) Stop() {
+	b.StopAccessAPI()
+	b.gateway = nil
+	b.emulator = nil
+	b.store.Stop()
+
+	select {
+	case <-b.stopped:
+	default:
+		close(b.stopped)
+	}
+}
+
+// Stopped returns a channel that is closed when the blockchain is stopped.
+func (b *Blockchain) Stopped() <-chan struct{} {
+	return b.stopped
+}
+
+// CreateSnapshot saves the current chain state under the given name.
+func (b *Blockchain) CreateSnapshot(name string) error {
+	return b.emulator.CreateSnapshot(name)
+}
+
+// LoadSnapshot reverts the chain state to the snapshot with the given name.
+func (b *Blockchain) LoadSnapshot(name string) error {
+	return b.emulator.LoadSnapshot(name)
+}
+
+// Snapshots returns the names of the snapshots in alphabetical order.
+func (b *Blockchain) Snapshots() ([]string, error) {
+	return b.store.Snapshots()
+}
+
+func (b *Blockchain) DeleteSnapshot(name string) error {
+	return b.store.DeleteSnapshot(name)
+}
+
+// Export writes the chain data, which can be imported into another blockchain.
+func (b *Blockchain) Export(w io.Writer) error {
+	return b.store.ExportJournal(w)
+}
+
+// Import loads chain data written by Export, the blockchain must not be started yet.
+func (b *Blockchain) Import(r io.Reader) error {
+	return b.store.ImportJournal(r)
+}
+
+// Committed returns a channel that is closed when the next block is committed,
+// or when the blockchain is reverted to a snapshot.
+func (b *Blockchain) Committed() <-chan struct{} {
+	return b.store.Committed()
+}
+
+// LatestBlockHeight returns the height of the latest committed block.
+func (b *Blockchain) LatestBlockHeight() (uint64, error) {
+	return b.store.LatestBlockHeight(context.Background())
+}
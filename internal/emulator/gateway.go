@@ -0,0 +1,311 @@
+package emulator
+
+import (
+	"context"
+	"fmt"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-emulator/adapters"
+	"github.com/onflow/flow-emulator/convert"
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-emulator/types"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flowkit/gateway"
+	"github.com/rs/zerolog"
+	"sync"
+)
+
+// maxTransactionExecutions is the number of execution results kept by the gateway,
+// which are only needed right after the transactions are sent.
+const maxTransactionExecutions = 100
+
+// Gateway implements the flowkit gateway on top of the emulator.
+// Unlike the flowkit emulator gateway, it executes each transaction in its own block
+// and keeps the execution results (e.g. computation used) that the emulator doesn't store.
+type Gateway struct {
+	emulator *emulator.Blockchain
+	adapter  *adapters.SDKAdapter
+
+	mu      sync.RWMutex
+	results map[flow.Identifier]*types.TransactionResult
+	// resultIDs are the transaction IDs of the results, from the oldest to the newest.
+	resultIDs []flow.Identifier
+	// commitMu serializes sending and committing transactions, so that each is committed in its own block,
+	// also when they are sent through the Access API.
+	commitMu sync.Mutex
+}
+
+var _ gateway.Gateway = &Gateway{}
+
+// ScriptError is returned if the script was executed, but failed (e.g. with a Cadence error),
+// as opposed to errors that prevented its execution.
+type ScriptError struct {
+	// Location identifies the script in the positions of the error message.
+	Location string
+	Err      error
+}
+
+func (e *ScriptError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+type scriptLogsKey struct{}
+
+// WithScriptLogs returns a context that collects the Cadence log() output of the scripts executed with it,
+// including scripts that failed.
+func WithScriptLogs(ctx context.Context, logs *[]string) context.Context {
+	return context.WithValue(ctx, scriptLogsKey{}, logs)
+}
+
+func newGateway(logger *zerolog.Logger, blockchain *emulator.Blockchain) *Gateway {
+	// Blocks are committed by the gateway, so that the transaction results can be captured.
+	blockchain.DisableAutoMine()
+
+	return &Gateway{
+		emulator: blockchain,
+		adapter:  adapters.NewSDKAdapter(logger, blockchain),
+		results:  make(map[flow.Identifier]*types.TransactionResult),
+	}
+}
+
+// TransactionExecution returns the execution result of a transaction recently sent through the gateway.
+func (g *Gateway) TransactionExecution(id flow.Identifier) (*types.TransactionResult, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	result, ok := g.results[id]
+	return result, ok
+}
+
+func (g *Gateway) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
+	account, err := g.adapter.GetAccount(ctx, address)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return account, nil
+}
+
+func (g *Gateway) SendSignedTransaction(ctx context.Context, tx *flow.Transaction) (*flow.Transaction, error) {
+	err := g.commitTransaction(func() error {
+		err := g.adapter.SendTransaction(ctx, *tx)
+		if err != nil {
+			return gateway.UnwrapStatusError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// commitTransaction sends a transaction with the given function and commits it in a new block,
+// keeping the execution results.
+func (g *Gateway) commitTransaction(send func() error) error {
+	g.commitMu.Lock()
+	defer g.commitMu.Unlock()
+
+	err := send()
+	if err != nil {
+		return err
+	}
+
+	_, results, err := g.emulator.ExecuteAndCommitBlock()
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	for _, result := range results {
+		g.results[result.TransactionID] = result
+		g.resultIDs = append(g.resultIDs, result.TransactionID)
+	}
+	for len(g.resultIDs) > maxTransactionExecutions {
+		delete(g.results, g.resultIDs[0])
+		g.resultIDs = g.resultIDs[1:]
+	}
+	g.mu.Unlock()
+
+	return nil
+}
+
+func (g *Gateway) GetTransactionResult(ctx context.Context, id flow.Identifier, _ bool) (*flow.TransactionResult, error) {
+	result, err := g.adapter.GetTransactionResult(ctx, id)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return result, nil
+}
+
+func (g *Gateway) GetTransaction(ctx context.Context, id flow.Identifier) (*flow.Transaction, error) {
+	transaction, err := g.adapter.GetTransaction(ctx, id)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return transaction, nil
+}
+
+func (g *Gateway) GetTransactionResultsByBlockID(ctx context.Context, id flow.Identifier) ([]*flow.TransactionResult, error) {
+	results, err := g.adapter.GetTransactionResultsByBlockID(ctx, id)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return results, nil
+}
+
+func (g *Gateway) GetTransactionsByBlockID(ctx context.Context, id flow.Identifier) ([]*flow.Transaction, error) {
+	transactions, err := g.adapter.GetTransactionsByBlockID(ctx, id)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return transactions, nil
+}
+
+func (g *Gateway) ExecuteScript(ctx context.Context, script []byte, arguments []cadence.Value) (cadence.Value, error) {
+	block, err := g.emulator.GetLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return g.executeScript(ctx, script, arguments, block.Header.Height)
+}
+
+func (g *Gateway) ExecuteScriptAtHeight(
+	ctx context.Context,
+	script []byte,
+	arguments []cadence.Value,
+	height uint64,
+) (cadence.Value, error) {
+	return g.executeScript(ctx, script, arguments, height)
+}
+
+func (g *Gateway) ExecuteScriptAtID(
+	ctx context.Context,
+	script []byte,
+	arguments []cadence.Value,
+	id flow.Identifier,
+) (cadence.Value, error) {
+	block, err := g.emulator.GetBlockByID(convert.SDKIdentifierToFlow(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return g.executeScript(ctx, script, arguments, block.Header.Height)
+}
+
+// executeScript runs the script against the state at the given block height.
+// Errors raised by the script itself are returned as ScriptError.
+func (g *Gateway) executeScript(ctx context.Context, script []byte, arguments []cadence.Value, height uint64) (cadence.Value, error) {
+	args, err := encodeArguments(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := g.emulator.ExecuteScriptAtBlockHeight(script, args, height)
+	if err != nil {
+		return nil, err
+	}
+
+	if logs, ok := ctx.Value(scriptLogsKey{}).(*[]string); ok {
+		*logs = append(*logs, result.Logs...)
+	}
+
+	if !result.Succeeded() {
+		return nil, &ScriptError{Location: fvm.Script(script).ID.String(), Err: result.Error}
+	}
+
+	return result.Value, nil
+}
+
+func (g *Gateway) GetLatestBlock(ctx context.Context) (*flow.Block, error) {
+	block, _, err := g.adapter.GetLatestBlock(ctx, true)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return block, nil
+}
+
+func (g *Gateway) GetBlockByHeight(ctx context.Context, height uint64) (*flow.Block, error) {
+	block, _, err := g.adapter.GetBlockByHeight(ctx, height)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return block, nil
+}
+
+func (g *Gateway) GetBlockByID(ctx context.Context, id flow.Identifier) (*flow.Block, error) {
+	block, _, err := g.adapter.GetBlockByID(ctx, id)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return block, nil
+}
+
+func (g *Gateway) GetEvents(
+	ctx context.Context,
+	eventType string,
+	startHeight uint64,
+	endHeight uint64,
+) ([]flow.BlockEvents, error) {
+	blockEvents, err := g.adapter.GetEventsForHeightRange(ctx, eventType, startHeight, endHeight)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+
+	events := make([]flow.BlockEvents, 0, len(blockEvents))
+	for _, e := range blockEvents {
+		events = append(events, *e)
+	}
+
+	return events, nil
+}
+
+func (g *Gateway) GetCollection(ctx context.Context, id flow.Identifier) (*flow.Collection, error) {
+	collection, err := g.adapter.GetCollectionByID(ctx, id)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return collection, nil
+}
+
+func (g *Gateway) GetLatestProtocolStateSnapshot(ctx context.Context) ([]byte, error) {
+	snapshot, err := g.adapter.GetLatestProtocolStateSnapshot(ctx)
+	if err != nil {
+		return nil, gateway.UnwrapStatusError(err)
+	}
+	return snapshot, nil
+}
+
+func (g *Gateway) Ping() error {
+	err := g.adapter.Ping(context.Background())
+	if err != nil {
+		return gateway.UnwrapStatusError(err)
+	}
+	return nil
+}
+
+func (g *Gateway) WaitServer(context.Context) error {
+	return nil
+}
+
+func (g *Gateway) SecureConnection() bool {
+	return false
+}
+
+func encodeArguments(values []cadence.Value) ([][]byte, error) {
+	args := make([][]byte, len(values))
+	for i, value := range values {
+		arg, err := jsoncdc.Encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode argument %d: %w", i, err)
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
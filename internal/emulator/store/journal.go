@@ -0,0 +1,355 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
+	flowgo "github.com/onflow/flow-go/model/flow"
+
+	"github.com/onflow/flow-emulator/types"
+)
+
+var ErrCommitTooLarge = errors.New("journal commit is too large")
+
+// maxCommitSize is the maximum encoded size of a commit, which protects against corrupted or malicious journals.
+const maxCommitSize = 64 << 20
+
+// Commit holds all data stored for a committed block.
+type Commit struct {
+	Block              flowgo.Block
+	Collections        []flowgo.LightCollection
+	Transactions       []flowgo.TransactionBody
+	TransactionResults map[flowgo.Identifier]types.StorableTransactionResult
+	// WriteSet holds the ledger registers updated by the block.
+	WriteSet map[flowgo.RegisterID]flowgo.RegisterValue
+	Events   []flowgo.Event
+}
+
+func newCommit(
+	block flowgo.Block,
+	collections []*flowgo.LightCollection,
+	transactions map[flowgo.Identifier]*flowgo.TransactionBody,
+	transactionResults map[flowgo.Identifier]*types.StorableTransactionResult,
+	executionSnapshot *snapshot.ExecutionSnapshot,
+	events []flowgo.Event,
+) Commit {
+	commit := Commit{
+		Block:              block,
+		Collections:        make([]flowgo.LightCollection, 0, len(collections)),
+		Transactions:       make([]flowgo.TransactionBody, 0, len(transactions)),
+		TransactionResults: make(map[flowgo.Identifier]types.StorableTransactionResult, len(transactionResults)),
+		Events:             events,
+	}
+
+	for _, col := range collections {
+		commit.Collections = append(commit.Collections, *col)
+	}
+
+	for _, tx := range transactions {
+		commit.Transactions = append(commit.Transactions, *tx)
+	}
+
+	for txID, result := range transactionResults {
+		commit.TransactionResults[txID] = *result
+	}
+
+	if executionSnapshot != nil {
+		commit.WriteSet = executionSnapshot.WriteSet
+	}
+
+	return commit
+}
+
+// ExportJournal writes all commits in the journal format, so that they can be imported into another store.
+// Persisted stores copy their journal file.
+func (s *InMemory) ExportJournal(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.journalPath != "" {
+		file, err := os.Open(s.journalPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(w, file)
+		return err
+	}
+
+	for _, commit := range s.commits {
+		err := writeCommit(w, commit)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportJournal loads the commits written by ExportJournal into a store that wasn't started yet.
+// Persisted stores write the commits to their journal file, from which they are replayed when started.
+func (s *InMemory) ImportJournal(r io.Reader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	commits := make([]Commit, 0)
+	for {
+		commit, _, err := readCommit(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read journal: %w", err)
+		}
+		commits = append(commits, commit)
+	}
+
+	if s.journalPath != "" {
+		err := os.MkdirAll(filepath.Dir(s.journalPath), 0755)
+		if err != nil {
+			return err
+		}
+
+		return writeJournal(s.journalPath, commits)
+	}
+
+	for _, commit := range commits {
+		err := s.applyCommit(commit)
+		if err != nil {
+			return err
+		}
+
+		s.commits = append(s.commits, commit)
+	}
+
+	return nil
+}
+
+// recordCommit appends the commit to the journal file, or keeps it in memory if the store isn't persisted.
+// A commit that fails to be written is removed from the journal file, so that later commits can still be appended.
+func (s *InMemory) recordCommit(commit Commit) error {
+	if s.journalPath == "" {
+		s.commits = append(s.commits, commit)
+		return nil
+	}
+
+	if s.journal == nil {
+		return nil
+	}
+
+	info, err := s.journal.Stat()
+	if err != nil {
+		return err
+	}
+
+	err = writeCommit(s.journal, commit)
+	if err != nil {
+		truncateErr := s.journal.Truncate(info.Size())
+		if truncateErr == nil {
+			_, truncateErr = s.journal.Seek(info.Size(), io.SeekStart)
+		}
+
+		return errors.Join(fmt.Errorf("failed to write journal %s: %w", s.journalPath, err), truncateErr)
+	}
+
+	return nil
+}
+
+// openJournal replays the commits from the journal file and opens it for appending.
+// A partially written commit at the end of the file (e.g. after a crash) is discarded,
+// unlike imported journals which must be complete.
+func (s *InMemory) openJournal() error {
+	err := os.MkdirAll(filepath.Dir(s.journalPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.journalPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(file)
+	var size int64
+
+	for {
+		commit, n, err := readCommit(reader)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to read journal %s: %w", s.journalPath, err)
+		}
+
+		err = s.applyCommit(commit)
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+
+		size += n
+	}
+
+	err = file.Truncate(size)
+	if err == nil {
+		_, err = file.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	s.journal = file
+
+	return s.loadSnapshots()
+}
+
+// replayJournal applies the commits of a complete journal file, e.g. of a snapshot.
+func (s *InMemory) replayJournal(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	for {
+		commit, _, err := readCommit(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = s.applyCommit(commit)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// restoreJournal replaces the journal file with the journal of the snapshot,
+// which is needed when the store is reverted to it.
+func (s *InMemory) restoreJournal(snapshotName string) error {
+	if s.journalPath == "" {
+		return nil
+	}
+
+	s.closeJournal()
+
+	err := copyFile(s.snapshotPath(snapshotName), s.journalPath)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.journalPath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	s.journal = file
+
+	return nil
+}
+
+func (s *InMemory) closeJournal() {
+	if s.journal == nil {
+		return
+	}
+
+	_ = s.journal.Close()
+	s.journal = nil
+}
+
+func writeJournal(path string, commits []Commit) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	for _, commit := range commits {
+		err = writeCommit(writer, commit)
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// writeCommit writes the gob encoded commit, prefixed by its length.
+// Each commit is encoded separately, so that commits can be appended to an existing journal.
+// Commits larger than readCommit accepts are rejected, since the journal couldn't be read back.
+func writeCommit(w io.Writer, commit Commit) error {
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(commit)
+	if err != nil {
+		return err
+	}
+
+	if buffer.Len() > maxCommitSize {
+		return fmt.Errorf("%w: block %d is %d bytes", ErrCommitTooLarge, commit.Block.Header.Height, buffer.Len())
+	}
+
+	record := binary.BigEndian.AppendUint64(nil, uint64(buffer.Len()))
+	record = append(record, buffer.Bytes()...)
+
+	_, err = w.Write(record)
+	return err
+}
+
+// readCommit reads a commit written by writeCommit and returns it with the number of bytes read.
+// io.EOF is returned at the end of the journal, and io.ErrUnexpectedEOF if the last commit is incomplete.
+func readCommit(r io.Reader) (Commit, int64, error) {
+	var header [8]byte
+	_, err := io.ReadFull(r, header[:])
+	if err != nil {
+		return Commit{}, 0, err
+	}
+
+	size := binary.BigEndian.Uint64(header[:])
+	if size > maxCommitSize {
+		return Commit{}, 0, fmt.Errorf("%w: %d bytes", ErrCommitTooLarge, size)
+	}
+
+	// The data is read without allocating the size up front, since it may be corrupted.
+	var data bytes.Buffer
+	_, err = io.CopyN(&data, r, int64(size))
+	if errors.Is(err, io.EOF) {
+		return Commit{}, 0, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return Commit{}, 0, err
+	}
+
+	var commit Commit
+	err = gob.NewDecoder(&data).Decode(&commit)
+	if err != nil {
+		return Commit{}, 0, err
+	}
+
+	return commit, int64(len(header)) + int64(size), nil
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
+	flowgo "github.com/onflow/flow-go/model/flow"
+)
+
+func exportTestJournal(t *testing.T) []byte {
+	t.Helper()
+
+	s := New()
+	commitTestBlock(t, s, 0, map[flowgo.RegisterID]flowgo.RegisterValue{
+		flowgo.NewRegisterID(testOwner, "register"): []byte("value"),
+	})
+
+	var journal bytes.Buffer
+	err := s.ExportJournal(&journal)
+	if err != nil {
+		t.Fatalf("failed to export journal: %s", err)
+	}
+
+	return journal.Bytes()
+}
+
+func TestImportJournal(t *testing.T) {
+	s := New()
+
+	err := s.ImportJournal(bytes.NewReader(exportTestJournal(t)))
+	if err != nil {
+		t.Fatalf("failed to import journal: %s", err)
+	}
+
+	if blocks := s.ChainCounts(0).Blocks; blocks != 1 {
+		t.Errorf("expected 1 block, got %d", blocks)
+	}
+}
+
+func TestImportTruncatedJournal(t *testing.T) {
+	journal := exportTestJournal(t)
+
+	for _, size := range []int{4, len(journal) - 1} {
+		err := New().ImportJournal(bytes.NewReader(journal[:size]))
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected unexpected EOF importing %d of %d bytes, got %v", size, len(journal), err)
+		}
+	}
+}
+
+func TestImportOversizedJournal(t *testing.T) {
+	journal := binary.BigEndian.AppendUint64(nil, 1<<62)
+
+	err := New().ImportJournal(bytes.NewReader(journal))
+	if !errors.Is(err, ErrCommitTooLarge) {
+		t.Errorf("expected commit too large error, got %v", err)
+	}
+}
+
+func TestOpenJournalDiscardsIncompleteCommit(t *testing.T) {
+	journal := exportTestJournal(t)
+	path := filepath.Join(t.TempDir(), "chain.journal")
+
+	err := os.WriteFile(path, append(journal, journal[:len(journal)-1]...), 0644)
+	if err != nil {
+		t.Fatalf("failed to write journal: %s", err)
+	}
+
+	s := NewPersistent(path)
+	err = s.Start()
+	if err != nil {
+		t.Fatalf("failed to start store: %s", err)
+	}
+	defer s.Stop()
+
+	if blocks := s.ChainCounts(0).Blocks; blocks != 1 {
+		t.Errorf("expected 1 block, got %d", blocks)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat journal: %s", err)
+	}
+
+	if info.Size() != int64(len(journal)) {
+		t.Errorf("expected journal to be truncated to %d bytes, got %d", len(journal), info.Size())
+	}
+}
+
+func TestPersistedSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain.journal")
+	register := flowgo.NewRegisterID(testOwner, "register")
+
+	s := NewPersistent(path)
+	err := s.Start()
+	if err != nil {
+		t.Fatalf("failed to start store: %s", err)
+	}
+
+	commitTestBlock(t, s, 0, map[flowgo.RegisterID]flowgo.RegisterValue{register: []byte("a")})
+
+	err = s.CreateSnapshot("first block")
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %s", err)
+	}
+
+	commitTestBlock(t, s, 1, map[flowgo.RegisterID]flowgo.RegisterValue{register: []byte("b")})
+	s.Stop()
+
+	restarted := NewPersistent(path)
+	err = restarted.Start()
+	if err != nil {
+		t.Fatalf("failed to restart store: %s", err)
+	}
+	defer restarted.Stop()
+
+	names, _ := restarted.Snapshots()
+	if len(names) != 1 || names[0] != "first block" {
+		t.Fatalf("expected snapshot to be restored, got %v", names)
+	}
+
+	err = restarted.LoadSnapshot("first block")
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %s", err)
+	}
+
+	if restarted.blockHeight != 0 {
+		t.Errorf("expected block height 0 after loading snapshot, got %d", restarted.blockHeight)
+	}
+
+	var journal bytes.Buffer
+	err = restarted.ExportJournal(&journal)
+	if err != nil {
+		t.Fatalf("failed to export journal: %s", err)
+	}
+
+	imported := New()
+	err = imported.ImportJournal(&journal)
+	if err != nil {
+		t.Fatalf("failed to import journal: %s", err)
+	}
+
+	if imported.blockHeight != 0 || imported.ChainCounts(1).Blocks != 0 {
+		t.Errorf("expected the reverted journal to end at block height 0, got %d", imported.blockHeight)
+	}
+
+	err = restarted.DeleteSnapshot("first block")
+	if err != nil {
+		t.Fatalf("failed to delete snapshot: %s", err)
+	}
+
+	if _, err := os.Stat(restarted.snapshotPath("first block")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected snapshot file to be removed, got %v", err)
+	}
+}
+
+func TestFailedJournalWriteIsNotCommitted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain.journal")
+	register := flowgo.NewRegisterID(testOwner, "register")
+
+	s := NewPersistent(path)
+	err := s.Start()
+	if err != nil {
+		t.Fatalf("failed to start store: %s", err)
+	}
+	defer s.Stop()
+
+	commitTestBlock(t, s, 0, map[flowgo.RegisterID]flowgo.RegisterValue{register: []byte("a")})
+
+	if len(s.commits) != 0 {
+		t.Errorf("expected persisted store to keep no commits in memory, got %d", len(s.commits))
+	}
+
+	// Writes fail once the journal is replaced by a read-only file.
+	readOnly, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open journal: %s", err)
+	}
+	s.closeJournal()
+	s.journal = readOnly
+
+	committed := s.Committed()
+
+	payload := flowgo.EmptyPayload()
+	err = s.CommitBlock(
+		context.Background(),
+		flowgo.Block{Header: &flowgo.Header{Height: 1, View: 1}, Payload: &payload},
+		nil,
+		nil,
+		nil,
+		&snapshot.ExecutionSnapshot{WriteSet: map[flowgo.RegisterID]flowgo.RegisterValue{register: []byte("b")}},
+		nil,
+	)
+	if err == nil {
+		t.Fatalf("expected commit to fail when the journal can't be written")
+	}
+
+	if _, err := s.BlockByHeight(context.Background(), 1); err == nil {
+		t.Errorf("block that failed to be written to the journal was stored")
+	}
+
+	select {
+	case <-committed:
+		t.Errorf("subscribers were notified of a block that failed to be written to the journal")
+	default:
+	}
+}
+
+func TestOversizedCommitIsNotWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain.journal")
+	register := flowgo.NewRegisterID(testOwner, "register")
+
+	s := NewPersistent(path)
+	err := s.Start()
+	if err != nil {
+		t.Fatalf("failed to start store: %s", err)
+	}
+
+	commitTestBlock(t, s, 0, map[flowgo.RegisterID]flowgo.RegisterValue{register: []byte("a")})
+
+	payload := flowgo.EmptyPayload()
+	err = s.CommitBlock(
+		context.Background(),
+		flowgo.Block{Header: &flowgo.Header{Height: 1, View: 1}, Payload: &payload},
+		nil,
+		nil,
+		nil,
+		&snapshot.ExecutionSnapshot{WriteSet: map[flowgo.RegisterID]flowgo.RegisterValue{register: make([]byte, maxCommitSize)}},
+		nil,
+	)
+	if !errors.Is(err, ErrCommitTooLarge) {
+		t.Fatalf("expected commit too large error, got %v", err)
+	}
+
+	commitTestBlock(t, s, 1, map[flowgo.RegisterID]flowgo.RegisterValue{register: []byte("b")})
+	s.Stop()
+
+	restarted := NewPersistent(path)
+	err = restarted.Start()
+	if err != nil {
+		t.Fatalf("failed to restore store: %s", err)
+	}
+	defer restarted.Stop()
+
+	if blocks := restarted.ChainCounts(1).Blocks; blocks != 2 {
+		t.Errorf("expected 2 restored blocks, got %d", blocks)
+	}
+}
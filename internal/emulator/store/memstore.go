@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"sync"
 
 	"github.com/onflow/flow-go/fvm/storage/snapshot"
@@ -53,6 +54,22 @@ type InMemory struct {
 	eventsByBlockHeight map[uint64][]flowgo.Event
 	// highest block height
 	blockHeight uint64
+	// cumulative counts of committed items by block height
+	chainCounts map[uint64]ChainCounts
+	// ledger summaries by block height, and the sizes of the current ledger registers they are derived from
+	ledgerSummaries map[uint64]LedgerSummary
+	registerSizes   map[flowgo.RegisterID]int
+	ledgerSize      int
+	// snapshots by name
+	snapshots map[string]*InMemory
+	// committed blocks since genesis, which are exported as journal if the store isn't persisted,
+	// persisted stores read their journal file instead
+	commits []Commit
+	// path of the file that commits are appended to, empty if the store isn't persisted
+	journalPath string
+	journal     *os.File
+	// committed is closed and replaced whenever a block is committed or a snapshot is loaded.
+	committed chan struct{}
 }
 
 type InMemoryJson struct {
@@ -63,6 +80,28 @@ type InMemoryJson struct {
 	TransactionResults  map[flowgo.Identifier]types.StorableTransactionResult `json:"transactionResults"`
 	EventsByBlockHeight map[uint64][]flowgo.Event                             `json:"eventsByBlockHeight"`
 	BlockHeight         uint64                                                `json:"blockHeight"`
+	Ledger              map[uint64]LedgerSummary                              `json:"ledger"`
+}
+
+// LedgerSummary describes the ledger registers at a block height, without their values.
+type LedgerSummary struct {
+	// RegistersUpdated is the number of registers written by the block, including deleted ones.
+	RegistersUpdated int `json:"registersUpdated"`
+	// RegistersDeleted is the number of registers the block set to an empty value.
+	RegistersDeleted int `json:"registersDeleted"`
+	// Registers is the number of non-empty registers in the ledger after the block.
+	Registers int `json:"registers"`
+	// Size is the total size of the register values in the ledger after the block, in bytes.
+	Size int `json:"size"`
+}
+
+// ChainCounts are the numbers of blocks, collections, transactions and events committed
+// up to and including a block height, so that pages of chain items can be located without walking the chain.
+type ChainCounts struct {
+	Blocks       int
+	Collections  int
+	Transactions int
+	Events       int
 }
 
 // New returns a new in-memory InMemory implementation.
@@ -76,12 +115,30 @@ func New() *InMemory {
 		transactionResults:  make(map[flowgo.Identifier]types.StorableTransactionResult),
 		ledger:              make(map[uint64]snapshot.SnapshotTree),
 		eventsByBlockHeight: make(map[uint64][]flowgo.Event),
+		chainCounts:         make(map[uint64]ChainCounts),
+		ledgerSummaries:     make(map[uint64]LedgerSummary),
+		registerSizes:       make(map[flowgo.RegisterID]int),
+		snapshots:           make(map[string]*InMemory),
+		committed:           make(chan struct{}),
 	}
 }
 
+// NewPersistent returns an InMemory store that is persisted to the given file.
+// All committed blocks are appended to the file, and replayed from it when the store is started.
+func NewPersistent(journalPath string) *InMemory {
+	s := New()
+	s.journalPath = journalPath
+	return s
+}
+
 var _ storage.Store = &InMemory{}
 
+// Json returns the store contents, which are encoded while holding the lock,
+// so that they are consistent with a single block height.
 func (s *InMemory) Json() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	inMemoryJson := InMemoryJson{
 		BlockIDToHeight:     s.blockIDToHeight,
 		Blocks:              s.blocks,
@@ -89,15 +146,28 @@ func (s *InMemory) Json() ([]byte, error) {
 		Transactions:        s.transactions,
 		TransactionResults:  s.transactionResults,
 		EventsByBlockHeight: s.eventsByBlockHeight,
+		BlockHeight:         s.blockHeight,
+		Ledger:              s.ledgerSummaries,
 	}
 	return json.Marshal(inMemoryJson)
 }
 
 func (s *InMemory) Start() error {
-	return nil
+	if s.journalPath == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.openJournal()
 }
 
 func (s *InMemory) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeJournal()
 }
 
 func (s *InMemory) LatestBlockHeight(ctx context.Context) (uint64, error) {
@@ -188,47 +258,134 @@ func (s *InMemory) CommitBlock(
 		)
 	}
 
-	err := s.storeBlock(&block)
+	commit := newCommit(block, collections, transactions, transactionResults, executionSnapshot, events)
+
+	// The commit is recorded first, so that a block that fails to be written to the journal
+	// is neither stored nor seen by subscribers, since it would be missing when the store is restored.
+	err := s.recordCommit(commit)
+	if err != nil {
+		return err
+	}
+
+	err = s.applyCommit(commit)
 	if err != nil {
 		return err
 	}
 
-	for _, col := range collections {
-		err := s.insertCollection(*col)
+	s.notifyCommitted()
+
+	return nil
+}
+
+// Committed returns a channel that is closed when the next block is committed,
+// or when the store is reverted to a snapshot.
+func (s *InMemory) Committed() <-chan struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.committed
+}
+
+func (s *InMemory) notifyCommitted() {
+	close(s.committed)
+	s.committed = make(chan struct{})
+}
+
+// applyCommit stores the data of a committed block.
+func (s *InMemory) applyCommit(commit Commit) error {
+	err := s.storeBlock(&commit.Block)
+	if err != nil {
+		return err
+	}
+
+	for _, col := range commit.Collections {
+		err := s.insertCollection(col)
 		if err != nil {
 			return err
 		}
 	}
 
-	for _, tx := range transactions {
-		err := s.insertTransaction(tx.ID(), *tx)
+	for _, tx := range commit.Transactions {
+		err := s.insertTransaction(tx.ID(), tx)
 		if err != nil {
 			return err
 		}
 	}
 
-	for txID, result := range transactionResults {
-		err := s.insertTransactionResult(txID, *result)
+	for txID, result := range commit.TransactionResults {
+		err := s.insertTransactionResult(txID, result)
 		if err != nil {
 			return err
 		}
 	}
 
 	err = s.insertExecutionSnapshot(
-		block.Header.Height,
-		executionSnapshot)
+		commit.Block.Header.Height,
+		&snapshot.ExecutionSnapshot{WriteSet: commit.WriteSet})
 	if err != nil {
 		return err
 	}
 
-	err = s.insertEvents(block.Header.Height, events)
+	err = s.insertEvents(commit.Block.Header.Height, commit.Events)
 	if err != nil {
 		return err
 	}
 
+	s.insertChainCounts(commit)
+	s.insertLedgerSummary(commit)
+
 	return nil
 }
 
+// ChainCounts returns the numbers of items committed up to and including the block height,
+// which are zero before the first committed block.
+func (s *InMemory) ChainCounts(blockHeight uint64) ChainCounts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.chainCounts[blockHeight]
+}
+
+// insertLedgerSummary summarizes the ledger after the register updates of the commit.
+func (s *InMemory) insertLedgerSummary(commit Commit) {
+	summary := LedgerSummary{
+		RegistersUpdated: len(commit.WriteSet),
+	}
+
+	for id, value := range commit.WriteSet {
+		s.ledgerSize += len(value) - s.registerSizes[id]
+
+		if len(value) == 0 {
+			summary.RegistersDeleted++
+			delete(s.registerSizes, id)
+		} else {
+			s.registerSizes[id] = len(value)
+		}
+	}
+
+	summary.Registers = len(s.registerSizes)
+	summary.Size = s.ledgerSize
+	s.ledgerSummaries[commit.Block.Header.Height] = summary
+}
+
+func (s *InMemory) insertChainCounts(commit Commit) {
+	height := commit.Block.Header.Height
+
+	var counts ChainCounts
+	if height > 0 {
+		counts = s.chainCounts[height-1]
+	}
+
+	counts.Blocks++
+	counts.Collections += len(commit.Collections)
+	for _, col := range commit.Collections {
+		counts.Transactions += len(col.Transactions)
+	}
+	counts.Events += len(commit.Events)
+
+	s.chainCounts[height] = counts
+}
+
 func (s *InMemory) CollectionByID(
 	ctx context.Context,
 	collectionID flowgo.Identifier,
@@ -277,6 +434,9 @@ func (s *InMemory) LedgerByHeight(
 	ctx context.Context,
 	blockHeight uint64,
 ) (snapshot.StorageSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return s.ledger[blockHeight], nil
 }
 
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
+	flowgo "github.com/onflow/flow-go/model/flow"
+)
+
+var testOwner = flowgo.HexToAddress("01")
+
+// commitTestBlock commits a block at the given height that writes the given registers.
+func commitTestBlock(t *testing.T, s *InMemory, height uint64, writeSet map[flowgo.RegisterID]flowgo.RegisterValue) {
+	t.Helper()
+
+	payload := flowgo.EmptyPayload()
+	block := flowgo.Block{
+		Header:  &flowgo.Header{Height: height, View: height},
+		Payload: &payload,
+	}
+
+	err := s.CommitBlock(
+		context.Background(),
+		block,
+		nil,
+		nil,
+		nil,
+		&snapshot.ExecutionSnapshot{WriteSet: writeSet},
+		nil,
+	)
+	if err != nil {
+		t.Errorf("failed to commit block %d: %s", height, err)
+	}
+}
+
+// decodedJson holds the fields of InMemoryJson that are checked by the tests,
+// blocks are kept encoded since the block header can't be decoded from JSON.
+type decodedJson struct {
+	Blocks      map[uint64]json.RawMessage `json:"blocks"`
+	BlockHeight uint64                     `json:"blockHeight"`
+	Ledger      map[uint64]LedgerSummary   `json:"ledger"`
+}
+
+func decodeJson(s *InMemory) (decodedJson, error) {
+	var decoded decodedJson
+
+	data, err := s.Json()
+	if err != nil {
+		return decoded, err
+	}
+
+	err = json.Unmarshal(data, &decoded)
+	return decoded, err
+}
+
+func TestJsonLedgerSummaries(t *testing.T) {
+	s := New()
+
+	first := flowgo.NewRegisterID(testOwner, "first")
+	second := flowgo.NewRegisterID(testOwner, "second")
+
+	commitTestBlock(t, s, 0, map[flowgo.RegisterID]flowgo.RegisterValue{
+		first:  []byte("abc"),
+		second: []byte("de"),
+	})
+	commitTestBlock(t, s, 1, map[flowgo.RegisterID]flowgo.RegisterValue{
+		first: []byte("abcdef"),
+	})
+	commitTestBlock(t, s, 2, map[flowgo.RegisterID]flowgo.RegisterValue{
+		second: {},
+	})
+
+	decoded, err := decodeJson(s)
+	if err != nil {
+		t.Fatalf("failed to export store: %s", err)
+	}
+
+	if decoded.BlockHeight != 2 {
+		t.Errorf("expected block height 2, got %d", decoded.BlockHeight)
+	}
+
+	expected := map[uint64]LedgerSummary{
+		0: {RegistersUpdated: 2, Registers: 2, Size: 5},
+		1: {RegistersUpdated: 1, Registers: 2, Size: 8},
+		2: {RegistersUpdated: 1, RegistersDeleted: 1, Registers: 1, Size: 6},
+	}
+
+	if len(decoded.Ledger) != len(expected) {
+		t.Fatalf("expected %d ledger summaries, got %d", len(expected), len(decoded.Ledger))
+	}
+
+	for height, summary := range expected {
+		if decoded.Ledger[height] != summary {
+			t.Errorf("expected ledger summary %+v at height %d, got %+v", summary, height, decoded.Ledger[height])
+		}
+	}
+}
+
+// TestConcurrentCommitsAndExports is meant to be run with -race,
+// it commits blocks at increasing heights, as the emulator does, while the store is exported and read.
+func TestConcurrentCommitsAndExports(t *testing.T) {
+	const blocks = 100
+
+	s := New()
+	register := flowgo.NewRegisterID(testOwner, "register")
+
+	var commits sync.WaitGroup
+	commits.Add(1)
+	go func() {
+		defer commits.Done()
+
+		for height := uint64(0); height < blocks; height++ {
+			commitTestBlock(t, s, height, map[flowgo.RegisterID]flowgo.RegisterValue{
+				register: []byte{byte(height)},
+			})
+		}
+	}()
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+
+	for r := 0; r < 2; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+
+			var lastHeight uint64
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				decoded, err := decodeJson(s)
+				if err != nil {
+					t.Errorf("failed to export store: %s", err)
+					return
+				}
+
+				// The export is consistent with a single point in time,
+				// so each committed block has a ledger summary.
+				if len(decoded.Ledger) != len(decoded.Blocks) {
+					t.Errorf("%d ledger summaries for %d blocks", len(decoded.Ledger), len(decoded.Blocks))
+				}
+
+				// Blocks are committed in order, so the export holds every block up to the block height.
+				if len(decoded.Blocks) > 0 && len(decoded.Blocks) != int(decoded.BlockHeight)+1 {
+					t.Errorf("%d blocks exported at block height %d", len(decoded.Blocks), decoded.BlockHeight)
+				}
+
+				if decoded.BlockHeight < lastHeight {
+					t.Errorf("block height decreased from %d to %d", lastHeight, decoded.BlockHeight)
+				}
+				lastHeight = decoded.BlockHeight
+
+				height, err := s.LatestBlockHeight(context.Background())
+				if err != nil {
+					continue
+				}
+
+				_, err = s.LedgerByHeight(context.Background(), height)
+				if err != nil {
+					t.Errorf("failed to read ledger at height %d: %s", height, err)
+				}
+			}
+		}()
+	}
+
+	commits.Wait()
+	close(done)
+	readers.Wait()
+
+	decoded, err := decodeJson(s)
+	if err != nil {
+		t.Fatalf("failed to export store: %s", err)
+	}
+
+	if decoded.BlockHeight != blocks-1 {
+		t.Errorf("expected block height %d, got %d", blocks-1, decoded.BlockHeight)
+	}
+
+	if len(decoded.Ledger) != blocks {
+		t.Fatalf("expected %d ledger summaries, got %d", blocks, len(decoded.Ledger))
+	}
+
+	for height, summary := range decoded.Ledger {
+		if summary.RegistersUpdated != 1 || summary.Registers != 1 || summary.Size != 1 {
+			t.Errorf("unexpected ledger summary %+v at height %d", summary, height)
+		}
+	}
+
+	for height := uint64(0); height < blocks; height++ {
+		ledger, err := s.LedgerByHeight(context.Background(), height)
+		if err != nil {
+			t.Fatalf("failed to read ledger at height %d: %s", height, err)
+		}
+		if ledger == nil {
+			t.Errorf("missing ledger at height %d", height)
+		}
+	}
+}
+
+func TestChainCounts(t *testing.T) {
+	s := New()
+	register := flowgo.NewRegisterID(testOwner, "register")
+
+	for height := uint64(0); height < 3; height++ {
+		commitTestBlock(t, s, height, map[flowgo.RegisterID]flowgo.RegisterValue{
+			register: []byte{byte(height)},
+		})
+
+		if height == 1 {
+			err := s.CreateSnapshot("second")
+			if err != nil {
+				t.Fatalf("failed to create snapshot: %s", err)
+			}
+		}
+	}
+
+	if counts := s.ChainCounts(2); counts.Blocks != 3 {
+		t.Errorf("expected 3 blocks at height 2, got %d", counts.Blocks)
+	}
+
+	err := s.LoadSnapshot("second")
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %s", err)
+	}
+
+	if counts := s.ChainCounts(1); counts.Blocks != 2 {
+		t.Errorf("expected 2 blocks at height 1, got %d", counts.Blocks)
+	}
+
+	if counts := s.ChainCounts(2); counts != (ChainCounts{}) {
+		t.Errorf("expected no counts at reverted height 2, got %+v", counts)
+	}
+}
@@ -0,0 +1,209 @@
+package store
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/onflow/flow-emulator/storage"
+)
+
+var _ storage.SnapshotProvider = &InMemory{}
+
+// Snapshots of persisted stores are saved as journal files to a directory next to the store journal.
+const (
+	snapshotDirectoryName = "snapshots"
+	snapshotFileExtension = ".journal"
+)
+
+// Snapshots returns the names of the snapshots in alphabetical order.
+func (s *InMemory) Snapshots() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.snapshots))
+	for name := range s.snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// CreateSnapshot saves a copy of the current store data under the given name,
+// replacing any existing snapshot with the same name.
+// Stored values are never modified in place, so copying the maps is sufficient.
+// Persisted stores also save a copy of the journal, from which the snapshot is restored when the store is started.
+func (s *InMemory) CreateSnapshot(snapshotName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.journalPath != "" {
+		err := copyFile(s.journalPath, s.snapshotPath(snapshotName))
+		if err != nil {
+			return err
+		}
+	}
+
+	s.snapshots[snapshotName] = s.copyData()
+
+	return nil
+}
+
+// LoadSnapshot replaces the current store data with a copy of the snapshot.
+// The snapshot is kept, so that the store can be reverted to it again.
+func (s *InMemory) LoadSnapshot(snapshotName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, ok := s.snapshots[snapshotName]
+	if !ok {
+		return fmt.Errorf("%w: snapshot %s", storage.ErrNotFound, snapshotName)
+	}
+
+	data := snapshot.copyData()
+	s.blockIDToHeight = data.blockIDToHeight
+	s.blocks = data.blocks
+	s.collections = data.collections
+	s.transactions = data.transactions
+	s.transactionResults = data.transactionResults
+	s.ledger = data.ledger
+	s.eventsByBlockHeight = data.eventsByBlockHeight
+	s.blockHeight = data.blockHeight
+	s.chainCounts = data.chainCounts
+	s.ledgerSummaries = data.ledgerSummaries
+	s.registerSizes = data.registerSizes
+	s.ledgerSize = data.ledgerSize
+	s.commits = data.commits
+
+	s.notifyCommitted()
+
+	return s.restoreJournal(snapshotName)
+}
+
+func (s *InMemory) DeleteSnapshot(snapshotName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.snapshots[snapshotName]; !ok {
+		return fmt.Errorf("%w: snapshot %s", storage.ErrNotFound, snapshotName)
+	}
+
+	delete(s.snapshots, snapshotName)
+
+	if s.journalPath != "" {
+		err := os.Remove(s.snapshotPath(snapshotName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *InMemory) SupportSnapshotsWithCurrentConfig() bool {
+	return true
+}
+
+// snapshotPath returns the journal file of the snapshot of a persisted store.
+// Names are hex encoded, since they may contain characters that aren't allowed in file names.
+func (s *InMemory) snapshotPath(snapshotName string) string {
+	return filepath.Join(s.snapshotDirectory(), hex.EncodeToString([]byte(snapshotName))+snapshotFileExtension)
+}
+
+func (s *InMemory) snapshotDirectory() string {
+	return filepath.Join(filepath.Dir(s.journalPath), snapshotDirectoryName)
+}
+
+// loadSnapshots replays the journals of the snapshots saved by a persisted store.
+func (s *InMemory) loadSnapshots() error {
+	entries, err := os.ReadDir(s.snapshotDirectory())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		encodedName, ok := strings.CutSuffix(entry.Name(), snapshotFileExtension)
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		snapshotName, err := hex.DecodeString(encodedName)
+		if err != nil {
+			continue
+		}
+
+		snapshot := New()
+		err = snapshot.replayJournal(filepath.Join(s.snapshotDirectory(), entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to load snapshot %s: %w", snapshotName, err)
+		}
+
+		s.snapshots[string(snapshotName)] = snapshot
+	}
+
+	return nil
+}
+
+// copyFile replaces the destination file with a copy of the source file.
+func copyFile(sourcePath string, destinationPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	err = os.MkdirAll(filepath.Dir(destinationPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	// The copy is replaced atomically, so that a crash can't leave a partially written file behind.
+	temporaryPath := destinationPath + ".tmp"
+	destination, err := os.Create(temporaryPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(destination, source)
+	if err != nil {
+		_ = destination.Close()
+		return err
+	}
+
+	err = destination.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryPath, destinationPath)
+}
+
+// copyData returns a store with a copy of the data, but without snapshots.
+func (s *InMemory) copyData() *InMemory {
+	return &InMemory{
+		blockIDToHeight:     maps.Clone(s.blockIDToHeight),
+		blocks:              maps.Clone(s.blocks),
+		collections:         maps.Clone(s.collections),
+		transactions:        maps.Clone(s.transactions),
+		transactionResults:  maps.Clone(s.transactionResults),
+		ledger:              maps.Clone(s.ledger),
+		eventsByBlockHeight: maps.Clone(s.eventsByBlockHeight),
+		blockHeight:         s.blockHeight,
+		chainCounts:         maps.Clone(s.chainCounts),
+		ledgerSummaries:     maps.Clone(s.ledgerSummaries),
+		registerSizes:       maps.Clone(s.registerSizes),
+		ledgerSize:          s.ledgerSize,
+		commits:             slices.Clone(s.commits),
+	}
+}
@@ -0,0 +1,220 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"path"
+)
+
+// Bundle holds everything needed to recreate the repository without access to its remote.
+type Bundle struct {
+	// Objects is a packfile with all git objects of the repository.
+	Objects []byte
+	// Config is the encoded git config, which includes the remotes.
+	Config     []byte
+	References []BundleReference
+	// Shallow lists the commits whose parents are missing from a shallow clone.
+	Shallow []string
+	Depth   int
+	// Files are the worktree files by path, including uncommitted changes.
+	Files map[string][]byte
+}
+
+type BundleReference struct {
+	Name string `json:"name"`
+	// Hash is set for hash references and Target for symbolic references (e.g. HEAD pointing to a branch).
+	Hash   string `json:"hash,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// Bundle exports the repository, including its worktree.
+func (r *Repository) Bundle() (*Bundle, error) {
+	if r.repository == nil {
+		return nil, ErrNotCloned
+	}
+
+	objects, err := r.storage.IterEncodedObjects(plumbing.AnyObject)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]plumbing.Hash, 0)
+	err = objects.ForEach(func(object plumbing.EncodedObject) error {
+		hashes = append(hashes, object.Hash())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pack bytes.Buffer
+	_, err = packfile.NewEncoder(&pack, r.storage, false).Encode(hashes, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	repositoryConfig, err := r.storage.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	encodedConfig, err := repositoryConfig.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{
+		Objects:    pack.Bytes(),
+		Config:     encodedConfig,
+		References: make([]BundleReference, 0),
+		Shallow:    make([]string, 0),
+		Depth:      r.depth,
+		Files:      make(map[string][]byte),
+	}
+
+	references, err := r.storage.IterReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	err = references.ForEach(func(reference *plumbing.Reference) error {
+		bundleReference := BundleReference{Name: reference.Name().String()}
+		if reference.Type() == plumbing.SymbolicReference {
+			bundleReference.Target = reference.Target().String()
+		} else {
+			bundleReference.Hash = reference.Hash().String()
+		}
+		bundle.References = append(bundle.References, bundleReference)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shallow, err := r.storage.Shallow()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hash := range shallow {
+		bundle.Shallow = append(bundle.Shallow, hash.String())
+	}
+
+	files, err := r.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if !file.IsDirectory {
+			bundle.Files[file.Path] = []byte(file.Content)
+		}
+	}
+
+	return bundle, nil
+}
+
+// Import recreates the repository from a bundle, instead of cloning it.
+// The worktree is checked out at HEAD and then replaced with the bundle files,
+// so that uncommitted changes show up as such.
+func (r *Repository) Import(bundle *Bundle) error {
+	fs, storage := r.newStorage()
+
+	err := packfile.UpdateObjectStorage(storage, bytes.NewReader(bundle.Objects))
+	if err != nil {
+		return err
+	}
+
+	repositoryConfig := config.NewConfig()
+	err = repositoryConfig.Unmarshal(bundle.Config)
+	if err != nil {
+		return err
+	}
+
+	err = storage.SetConfig(repositoryConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, bundleReference := range bundle.References {
+		name := plumbing.ReferenceName(bundleReference.Name)
+
+		reference := plumbing.NewHashReference(name, plumbing.NewHash(bundleReference.Hash))
+		if bundleReference.Target != "" {
+			reference = plumbing.NewSymbolicReference(name, plumbing.ReferenceName(bundleReference.Target))
+		}
+
+		err = storage.SetReference(reference)
+		if err != nil {
+			return err
+		}
+	}
+
+	shallow := make([]plumbing.Hash, 0, len(bundle.Shallow))
+	for _, hash := range bundle.Shallow {
+		shallow = append(shallow, plumbing.NewHash(hash))
+	}
+
+	err = storage.SetShallow(shallow)
+	if err != nil {
+		return err
+	}
+
+	repository, err := git.Open(storage, fs)
+	if err != nil {
+		return err
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return err
+	}
+
+	head, err := repository.Head()
+	if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+
+	if head != nil {
+		err = worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset})
+		if err != nil {
+			return err
+		}
+	}
+
+	r.repository = repository
+	r.fs = fs
+	r.storage = storage
+	r.depth = bundle.Depth
+
+	return r.replaceFiles(bundle.Files)
+}
+
+// replaceFiles replaces all worktree files with the given files.
+func (r *Repository) replaceFiles(files map[string][]byte) error {
+	entries, err := r.fs.ReadDir("/")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = util.RemoveAll(r.fs, path.Join("/", entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	for filePath, content := range files {
+		err = r.WriteFile(filePath, content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"regexp"
+)
+
+var ErrReferenceNotFound = errors.New("reference not found")
+var ErrNotCloned = errors.New("repository is not cloned")
+var ErrUncommittedChanges = errors.New("working tree contains uncommitted changes")
+
+var commitHashPattern = regexp.MustCompile("^[0-9a-fA-F]{4,40}$")
+
+// Checkout switches the working tree to the given branch, tag or commit SHA.
+// Refs that weren't fetched by a shallow or single-branch clone are fetched from the origin remote.
+// Uncommitted changes are discarded only if force is set, otherwise ErrUncommittedChanges is returned.
+func (r *Repository) Checkout(ref string, force bool) error {
+	worktree, err := r.repository.Worktree()
+
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		status, err := worktree.Status()
+
+		if err != nil {
+			return err
+		}
+
+		if !status.IsClean() {
+			return ErrUncommittedChanges
+		}
+	}
+
+	checkoutOptions, err := r.checkoutOptions(ref)
+
+	if errors.Is(err, ErrReferenceNotFound) {
+		err = r.fetchAll()
+
+		if err != nil {
+			return err
+		}
+
+		checkoutOptions, err = r.checkoutOptions(ref)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	checkoutOptions.Force = force
+
+	err = worktree.Checkout(checkoutOptions)
+
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info().Msg(fmt.Sprintf("Checked out %s", ref))
+
+	return nil
+}
+
+// Head returns the commit hash the working tree is based on.
+func (r *Repository) Head() (string, error) {
+	if r.repository == nil {
+		return "", ErrNotCloned
+	}
+
+	head, err := r.repository.Head()
+
+	if err != nil {
+		return "", err
+	}
+
+	return head.Hash().String(), nil
+}
+
+func (r *Repository) checkoutOptions(ref string) (*git.CheckoutOptions, error) {
+	localBranch := plumbing.NewBranchReferenceName(ref)
+
+	_, err := r.repository.Reference(localBranch, false)
+
+	if err == nil {
+		return &git.CheckoutOptions{Branch: localBranch}, nil
+	}
+
+	remoteBranch, err := r.repository.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, ref), true)
+
+	if err == nil {
+		return &git.CheckoutOptions{Branch: localBranch, Hash: remoteBranch.Hash(), Create: true}, nil
+	}
+
+	// Tags and commit hashes are checked out as a detached HEAD.
+	hash, err := r.repository.ResolveRevision(plumbing.Revision(ref))
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrReferenceNotFound, ref)
+	}
+
+	return &git.CheckoutOptions{Hash: *hash}, nil
+}
+
+func (r *Repository) fetchAll() error {
+	err := r.repository.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", git.DefaultRemoteName)),
+		},
+		Depth: r.depth,
+		Tags:  git.AllTags,
+	})
+
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+
+	return err
+}
+
+// remoteReferenceName finds the full name of a branch or a tag on the remote repository.
+func remoteReferenceName(url string, ref string) (plumbing.ReferenceName, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
+	})
+
+	references, err := remote.List(&git.ListOptions{})
+
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(ref),
+		plumbing.NewTagReferenceName(ref),
+		plumbing.ReferenceName(ref),
+	}
+
+	for _, candidate := range candidates {
+		for _, reference := range references {
+			if reference.Name() == candidate {
+				return candidate, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrReferenceNotFound, ref)
+}
+
+func isCommitHash(ref string) bool {
+	return commitHashPattern.MatchString(ref)
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"os"
+	"path"
+)
+
+// Directory exposes a sub-directory of the repository as the file system root.
+// It implements flowkit.ReaderWriter for projects where flow.json isn't in the repository root.
+type Directory struct {
+	repository *Repository
+	root       string
+}
+
+func (r *Repository) Directory(root string) *Directory {
+	return &Directory{
+		repository: r,
+		root:       path.Join("/", root),
+	}
+}
+
+func (d *Directory) ReadFile(source string) ([]byte, error) {
+	return d.repository.ReadFile(d.path(source))
+}
+
+func (d *Directory) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	return d.repository.WriteFile(d.path(filename), data, perm)
+}
+
+func (d *Directory) MkdirAll(dirPath string, perm os.FileMode) error {
+	return d.repository.MkdirAll(d.path(dirPath), perm)
+}
+
+func (d *Directory) Stat(filePath string) (os.FileInfo, error) {
+	return d.repository.Stat(d.path(filePath))
+}
+
+// path returns the repository path of a file given relative to the directory.
+func (d *Directory) path(filePath string) string {
+	return path.Join(d.root, filePath)
+}
@@ -0,0 +1,417 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+type FileStatus struct {
+	Path string `json:"path"`
+	// Status of the file in the working tree compared to HEAD,
+	// one of "added", "modified", "deleted" or "renamed".
+	Status string `json:"status"`
+	// Staged is true if the change is already added to the index.
+	Staged bool `json:"staged"`
+}
+
+type FileDiff struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	// Diff of the file in the unified diff format.
+	Diff string `json:"diff"`
+}
+
+type CommitInfo struct {
+	Hash        string    `json:"hash"`
+	Message     string    `json:"message"`
+	AuthorName  string    `json:"authorName"`
+	AuthorEmail string    `json:"authorEmail"`
+	AuthoredAt  time.Time `json:"authoredAt"`
+	Parents     []string  `json:"parents"`
+}
+
+// Status returns all files in the working tree that differ from HEAD, ordered by path.
+func (r *Repository) Status() ([]FileStatus, error) {
+	worktree, err := r.repository.Worktree()
+
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := worktree.Status()
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileStatus, 0, len(status))
+
+	for filePath, fileStatus := range status {
+		code := fileStatus.Worktree
+		staged := false
+		if code == git.Unmodified {
+			code = fileStatus.Staging
+			staged = true
+		}
+
+		statusName := statusCodeName(code)
+
+		if statusName == "" {
+			continue
+		}
+
+		files = append(files, FileStatus{
+			Path:   "/" + filePath,
+			Status: statusName,
+			Staged: staged,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files, nil
+}
+
+// Diff returns unified diffs between HEAD and the working tree for the given paths,
+// or for all changed files if no paths are provided.
+func (r *Repository) Diff(paths ...string) ([]FileDiff, error) {
+	status, err := r.Status()
+
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool)
+	for _, filePath := range paths {
+		selected["/"+relativePath(filePath)] = true
+	}
+
+	headTree, err := r.headTree()
+
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]FileDiff, 0)
+
+	for _, fileStatus := range status {
+		if len(selected) > 0 && !selected[fileStatus.Path] {
+			continue
+		}
+
+		unifiedDiff, err := r.fileDiff(headTree, relativePath(fileStatus.Path))
+
+		if err != nil {
+			return nil, err
+		}
+
+		diffs = append(diffs, FileDiff{
+			Path:   fileStatus.Path,
+			Status: fileStatus.Status,
+			Diff:   unifiedDiff,
+		})
+	}
+
+	return diffs, nil
+}
+
+// Log returns up to limit commits reachable from HEAD, optionally only the ones touching the given path.
+func (r *Repository) Log(limit int, filePath string) ([]CommitInfo, error) {
+	head, err := r.repository.Head()
+
+	if err != nil {
+		return nil, err
+	}
+
+	headCommit, err := r.repository.CommitObject(head.Hash())
+
+	if err != nil {
+		return nil, err
+	}
+
+	shallowParents, err := r.shallowParents()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Commits are listed in committer time order, as by git log.
+	iter := object.NewCommitIterCTime(headCommit, shallowParents, nil)
+
+	if filePath != "" {
+		relPath := relativePath(filePath)
+		iter = object.NewCommitPathIterFromIter(func(p string) bool {
+			return p == relPath || strings.HasPrefix(p, relPath+"/")
+		}, iter, false)
+	}
+
+	defer iter.Close()
+
+	commits := make([]CommitInfo, 0)
+
+	for limit <= 0 || len(commits) < limit {
+		commit, err := iter.Next()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+
+		parents := make([]string, 0, len(commit.ParentHashes))
+		for _, parent := range commit.ParentHashes {
+			parents = append(parents, parent.String())
+		}
+
+		commits = append(commits, CommitInfo{
+			Hash:        commit.Hash.String(),
+			Message:     commit.Message,
+			AuthorName:  commit.Author.Name,
+			AuthorEmail: commit.Author.Email,
+			AuthoredAt:  commit.Author.When,
+			Parents:     parents,
+		})
+	}
+
+	return commits, nil
+}
+
+// shallowParents returns the parents of the oldest commits of a shallow clone, which aren't fetched,
+// so that the history can be walked without them.
+func (r *Repository) shallowParents() (map[plumbing.Hash]bool, error) {
+	shallow, err := r.repository.Storer.Shallow()
+
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[plumbing.Hash]bool)
+
+	for _, hash := range shallow {
+		commit, err := r.repository.CommitObject(hash)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, parent := range commit.ParentHashes {
+			parents[parent] = true
+		}
+	}
+
+	return parents, nil
+}
+
+// FileAtRevision returns the file contents at the given revision (branch, tag, commit SHA or expressions like HEAD~1).
+func (r *Repository) FileAtRevision(revision string, filePath string) (*RepositoryFile, error) {
+	hash, err := r.repository.ResolveRevision(plumbing.Revision(revision))
+
+	if err != nil {
+		return nil, ErrRevisionNotFound
+	}
+
+	commit, err := r.repository.CommitObject(*hash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := commit.File(relativePath(filePath))
+
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, os.ErrNotExist
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := file.Contents()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &RepositoryFile{
+		Path:        "/" + relativePath(filePath),
+		IsDirectory: false,
+		Content:     content,
+	}, nil
+}
+
+func (r *Repository) headTree() (*object.Tree, error) {
+	head, err := r.repository.Head()
+
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := r.repository.CommitObject(head.Hash())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return commit.Tree()
+}
+
+func (r *Repository) fileDiff(headTree *object.Tree, filePath string) (string, error) {
+	var from, to *diffFile
+	var fromContent, toContent string
+
+	headFile, err := headTree.File(filePath)
+
+	if err == nil {
+		fromContent, err = headFile.Contents()
+		if err != nil {
+			return "", err
+		}
+		from = &diffFile{path: filePath, hash: headFile.Hash, mode: headFile.Mode}
+	} else if !errors.Is(err, object.ErrFileNotFound) {
+		return "", err
+	}
+
+	worktreeContent, err := r.ReadFile("/" + filePath)
+
+	if err == nil {
+		toContent = string(worktreeContent)
+		to = &diffFile{
+			path: filePath,
+			hash: plumbing.ComputeHash(plumbing.BlobObject, worktreeContent),
+			mode: filemode.Regular,
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	chunks := make([]fdiff.Chunk, 0)
+	for _, d := range diff.Do(fromContent, toContent) {
+		var op fdiff.Operation
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			op = fdiff.Equal
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		}
+		chunks = append(chunks, diffChunk{content: d.Text, operation: op})
+	}
+
+	var sb strings.Builder
+	err = fdiff.NewUnifiedEncoder(&sb, fdiff.DefaultContextLines).Encode(diffPatch{
+		filePatches: []fdiff.FilePatch{diffFilePatch{from: from, to: to, chunks: chunks}},
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+func statusCodeName(code git.StatusCode) string {
+	switch code {
+	case git.Added, git.Untracked:
+		return "added"
+	case git.Modified, git.UpdatedButUnmerged:
+		return "modified"
+	case git.Deleted:
+		return "deleted"
+	case git.Renamed, git.Copied:
+		return "renamed"
+	default:
+		return ""
+	}
+}
+
+// The types below implement the go-git patch interfaces,
+// so that working tree changes can be encoded with the unified diff encoder.
+
+type diffPatch struct {
+	filePatches []fdiff.FilePatch
+}
+
+func (p diffPatch) FilePatches() []fdiff.FilePatch {
+	return p.filePatches
+}
+
+func (p diffPatch) Message() string {
+	return ""
+}
+
+type diffFilePatch struct {
+	from   *diffFile
+	to     *diffFile
+	chunks []fdiff.Chunk
+}
+
+func (p diffFilePatch) IsBinary() bool {
+	return false
+}
+
+func (p diffFilePatch) Files() (fdiff.File, fdiff.File) {
+	// Nil pointers must be returned as untyped nil interfaces,
+	// which is how the encoder detects added and deleted files.
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func (p diffFilePatch) Chunks() []fdiff.Chunk {
+	return p.chunks
+}
+
+type diffFile struct {
+	path string
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+func (f *diffFile) Hash() plumbing.Hash {
+	return f.hash
+}
+
+func (f *diffFile) Mode() filemode.FileMode {
+	return f.mode
+}
+
+func (f *diffFile) Path() string {
+	return f.path
+}
+
+type diffChunk struct {
+	content   string
+	operation fdiff.Operation
+}
+
+func (c diffChunk) Content() string {
+	return c.content
+}
+
+func (c diffChunk) Type() fdiff.Operation {
+	return c.operation
+}
@@ -0,0 +1,133 @@
+package git
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// newTestHistory returns the URL of a remote whose master branch has the given number of commits,
+// each of which changes main.cdc, and the commit hashes from the newest to the oldest.
+func newTestHistory(t *testing.T, commits int) (string, []string) {
+	t.Helper()
+
+	url := newTestRemote(t, map[string]string{"main.cdc": "0"}, nil)
+
+	repository := New(&testLogger)
+	err := repository.Clone(CloneOptions{URL: url})
+	if err != nil {
+		t.Fatalf("failed to clone: %s", err)
+	}
+	defer repository.Close()
+
+	head, err := repository.Head()
+	if err != nil {
+		t.Fatalf("failed to get head: %s", err)
+	}
+
+	hashes := []string{head}
+	for i := 1; i < commits; i++ {
+		err = repository.WriteFile("/main.cdc", []byte{byte('0' + i)}, 0644)
+		if err != nil {
+			t.Fatalf("failed to write main.cdc: %s", err)
+		}
+
+		hash, err := repository.Commit(CommitOptions{AuthorName: "Test", AuthorEmail: "test@example.com", Message: "Change main.cdc"})
+		if err != nil {
+			t.Fatalf("failed to commit: %s", err)
+		}
+
+		hashes = append([]string{hash}, hashes...)
+	}
+
+	_, err = repository.Push(Credentials{})
+	if err != nil {
+		t.Fatalf("failed to push: %s", err)
+	}
+
+	return url, hashes
+}
+
+func TestLog(t *testing.T) {
+	url, hashes := newTestHistory(t, 3)
+
+	tests := []struct {
+		name     string
+		depth    int
+		limit    int
+		filePath string
+		expected []string
+	}{
+		{
+			name:     "full history",
+			expected: hashes,
+		},
+		{
+			name:     "limit",
+			limit:    2,
+			expected: hashes[:2],
+		},
+		{
+			name:     "path",
+			filePath: "/main.cdc",
+			expected: hashes,
+		},
+		{
+			name:     "other path",
+			filePath: "/other.cdc",
+			expected: []string{},
+		},
+		{
+			name:     "shallow",
+			depth:    1,
+			expected: hashes[:1],
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := New(&testLogger)
+			err := repository.Clone(CloneOptions{URL: url, Depth: test.depth})
+			if err != nil {
+				t.Fatalf("failed to clone: %s", err)
+			}
+			defer repository.Close()
+
+			commits, err := repository.Log(test.limit, test.filePath)
+			if err != nil {
+				t.Fatalf("failed to get log: %s", err)
+			}
+
+			if len(commits) != len(test.expected) {
+				t.Fatalf("expected %d commits, got %d", len(test.expected), len(commits))
+			}
+
+			for i, commit := range commits {
+				if commit.Hash != test.expected[i] {
+					t.Errorf("expected commit %d to be %s, got %s", i, test.expected[i], commit.Hash)
+				}
+			}
+		})
+	}
+}
+
+func TestLogBrokenHistory(t *testing.T) {
+	url, hashes := newTestHistory(t, 3)
+
+	repository := New(&testLogger)
+	err := repository.Clone(CloneOptions{URL: url})
+	if err != nil {
+		t.Fatalf("failed to clone: %s", err)
+	}
+	defer repository.Close()
+
+	// The parent of HEAD is removed from the object store, which breaks the history behind it.
+	delete(repository.storage.(*memory.Storage).ObjectStorage.Objects, plumbing.NewHash(hashes[1]))
+
+	commits, err := repository.Log(0, "")
+	if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		t.Errorf("expected reading a broken history to fail with ErrObjectNotFound, got %v and %d commits", err, len(commits))
+	}
+}
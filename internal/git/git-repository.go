@@ -1,21 +1,47 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/rs/zerolog"
+	"io"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
+// ErrSymlink is returned for paths through symbolic links, which are never followed
+// since they could point to files outside the worktree.
+var ErrSymlink = errors.New("path contains a symbolic link")
+
+var ErrMoveIntoItself = errors.New("cannot move a directory into itself")
+
 type Repository struct {
 	logger     *zerolog.Logger
 	repository *git.Repository
-	storage    *memory.Storage
+	storage    storage.Storer
 	fs         billy.Filesystem
+	// depth of the history that was cloned, zero if the full history is available.
+	depth int
+	// directory that the worktree and git objects are stored in, empty if they are kept in memory.
+	directory string
 }
 
 type RepositoryFile struct {
@@ -30,12 +56,96 @@ func New(logger *zerolog.Logger) *Repository {
 	}
 }
 
-func (r *Repository) Clone(url string) error {
-	fs := memfs.New()
-	storage := memory.NewStorage()
-	repository, err := git.Clone(storage, fs, &git.CloneOptions{
-		URL: url,
-	})
+// NewOnDisk returns a repository that stores the worktree and git objects in the given directory,
+// so that it can be reopened after a restart.
+func NewOnDisk(logger *zerolog.Logger, directory string) *Repository {
+	return &Repository{
+		logger:    logger,
+		directory: directory,
+	}
+}
+
+type CloneOptions struct {
+	URL string
+	// Ref is a branch, tag or commit SHA to check out, the remote HEAD is used if empty.
+	Ref string
+	// Depth limits the fetched history to the given number of commits, full history is fetched if zero.
+	Depth int
+}
+
+func (r *Repository) Clone(options CloneOptions) error {
+	cloneOptions := &git.CloneOptions{
+		URL:   options.URL,
+		Depth: options.Depth,
+	}
+
+	checkoutCommit := false
+
+	if options.Ref != "" {
+		referenceName, err := remoteReferenceName(options.URL, options.Ref)
+
+		switch {
+		case err == nil:
+			cloneOptions.ReferenceName = referenceName
+			cloneOptions.SingleBranch = true
+		case errors.Is(err, ErrReferenceNotFound) && isCommitHash(options.Ref):
+			// Arbitrary commits can't be fetched by hash, so the full history is needed to find it.
+			cloneOptions.Depth = 0
+			checkoutCommit = true
+		default:
+			return err
+		}
+	}
+
+	fs, storage := r.newStorage()
+	repository, err := git.Clone(storage, fs, cloneOptions)
+
+	if err != nil {
+		return err
+	}
+
+	if checkoutCommit {
+		hash, err := repository.ResolveRevision(plumbing.Revision(options.Ref))
+
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrReferenceNotFound, options.Ref)
+		}
+
+		worktree, err := repository.Worktree()
+
+		if err != nil {
+			return err
+		}
+
+		err = worktree.Checkout(&git.CheckoutOptions{Hash: *hash})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	r.repository = repository
+	r.fs = fs
+	r.storage = storage
+	r.depth = cloneOptions.Depth
+
+	return nil
+}
+
+// Depth returns the depth of the cloned history, zero if the full history is available.
+func (r *Repository) Depth() int {
+	return r.depth
+}
+
+// Open opens the repository previously cloned to the on-disk directory,
+// with the depth that was used to clone it.
+func (r *Repository) Open(depth int) error {
+	if r.directory == "" {
+		return fmt.Errorf("in-memory repository can't be reopened")
+	}
+
+	fs, storage := r.newStorage()
+	repository, err := git.Open(storage, fs)
 
 	if err != nil {
 		return err
@@ -44,23 +154,219 @@ func (r *Repository) Clone(url string) error {
 	r.repository = repository
 	r.fs = fs
 	r.storage = storage
+	r.depth = depth
+
+	return nil
+}
+
+// newStorage returns the worktree filesystem and git object storage,
+// on disk if the repository has a directory and in memory otherwise.
+func (r *Repository) newStorage() (billy.Filesystem, storage.Storer) {
+	if r.directory == "" {
+		return memfs.New(), memory.NewStorage()
+	}
+
+	// Bound filesystems resolve symbolic links within their directory,
+	// so that links in the repository can't reach other files on the host.
+	fs := osfs.New(filepath.Join(r.directory, "worktree"), osfs.WithBoundOS())
+	dotGit := osfs.New(filepath.Join(r.directory, "git"), osfs.WithBoundOS())
+
+	return fs, filesystem.NewStorage(dotGit, cache.NewObjectLRUDefault())
+}
+
+// checkSymlinks returns ErrSymlink if any existing element of the path is a symbolic link.
+func (r *Repository) checkSymlinks(filePath string) error {
+	current := "/"
+
+	for _, element := range strings.Split(relativePath(filePath), "/") {
+		if element == "" {
+			continue
+		}
+
+		current = path.Join(current, element)
+
+		// Bound filesystems take absolute paths of Lstat as host paths, so the path is given relative to the root.
+		stat, err := r.fs.Lstat(relativePath(current))
+
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if stat.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s", ErrSymlink, current)
+		}
+	}
 
 	return nil
 }
 
 func (r *Repository) Stat(path string) (os.FileInfo, error) {
+	err := r.checkSymlinks(path)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return r.fs.Stat(path)
 }
 
 func (r *Repository) MkdirAll(path string, perm os.FileMode) error {
-	panic("Unimplemented")
+	err := r.checkSymlinks(path)
+
+	if err != nil {
+		return err
+	}
+
+	return r.fs.MkdirAll(path, perm)
 }
 
 func (r *Repository) WriteFile(filename string, data []byte, perm os.FileMode) error {
-	panic("Unimplemented")
+	err := r.checkSymlinks(filename)
+
+	if err != nil {
+		return err
+	}
+
+	err = r.fs.MkdirAll(path.Dir(filename), os.ModeDir|0755)
+
+	if err != nil {
+		return err
+	}
+
+	return util.WriteFile(r.fs, filename, data, perm)
+}
+
+// Rename moves a file or a directory (including its contents) to a new path.
+func (r *Repository) Rename(from string, to string) error {
+	err := r.checkSymlinks(from)
+
+	if err != nil {
+		return err
+	}
+
+	err = r.checkSymlinks(to)
+
+	if err != nil {
+		return err
+	}
+
+	if to == from || strings.HasPrefix(to, from+"/") {
+		return fmt.Errorf("%w: %s to %s", ErrMoveIntoItself, from, to)
+	}
+
+	_, err = r.fs.Stat(from)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = r.fs.Stat(to)
+
+	if err == nil {
+		return os.ErrExist
+	}
+
+	err = r.fs.MkdirAll(path.Dir(to), os.ModeDir|0755)
+
+	if err != nil {
+		return err
+	}
+
+	// The in-memory filesystem renames every path that starts with the same characters (e.g. /ab when renaming /a),
+	// so its entries are moved one by one.
+	if r.directory == "" {
+		return r.move(from, to)
+	}
+
+	return r.fs.Rename(from, to)
+}
+
+// move moves the file, link or directory subtree to the new path, which must not exist.
+func (r *Repository) move(from string, to string) error {
+	stat, err := r.fs.Lstat(from)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case stat.Mode()&os.ModeSymlink != 0:
+		target, err := r.fs.Readlink(from)
+
+		if err != nil {
+			return err
+		}
+
+		err = r.fs.Symlink(target, to)
+
+		if err != nil {
+			return err
+		}
+	case stat.IsDir():
+		err = r.fs.MkdirAll(to, stat.Mode().Perm())
+
+		if err != nil {
+			return err
+		}
+
+		entries, err := r.fs.ReadDir(from)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			err = r.move(path.Join(from, entry.Name()), path.Join(to, entry.Name()))
+
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		content, err := util.ReadFile(r.fs, from)
+
+		if err != nil {
+			return err
+		}
+
+		err = util.WriteFile(r.fs, to, content, stat.Mode().Perm())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.fs.Remove(from)
+}
+
+// Remove deletes a file or a directory (including its contents).
+func (r *Repository) Remove(path string) error {
+	err := r.checkSymlinks(path)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = r.fs.Stat(path)
+
+	if err != nil {
+		return err
+	}
+
+	return util.RemoveAll(r.fs, path)
 }
 
 func (r *Repository) ReadFile(path string) ([]byte, error) {
+	err := r.checkSymlinks(path)
+
+	if err != nil {
+		return nil, err
+	}
+
 	f, err := r.fs.OpenFile(path, os.O_RDONLY, 0)
 
 	if err != nil {
@@ -74,22 +380,41 @@ func (r *Repository) ReadFile(path string) ([]byte, error) {
 		}
 	}(f)
 
-	stat, err := r.fs.Stat(path)
+	// Read the whole file, since a single Read call doesn't fill the buffer
+	// for empty or large files.
+	return io.ReadAll(f)
+}
+
+func (r *Repository) File(path string) (*RepositoryFile, error) {
+	stat, err := r.Stat(path)
+
 	if err != nil {
 		return nil, err
 	}
-	content := make([]byte, stat.Size())
 
-	_, err = f.Read(content)
+	if stat.IsDir() {
+		return &RepositoryFile{
+			Path:        path,
+			IsDirectory: true,
+			Content:     "",
+		}, nil
+	}
+
+	content, err := r.ReadFile(path)
+
 	if err != nil {
 		return nil, err
 	}
 
-	return content, nil
+	return &RepositoryFile{
+		Path:        path,
+		IsDirectory: false,
+		Content:     string(content),
+	}, nil
 }
 
 func (r *Repository) Files() ([]RepositoryFile, error) {
-	return r.recursiveFiles(r.fs.Root())
+	return r.recursiveFiles("/")
 }
 
 func (r *Repository) recursiveFiles(dir string) ([]RepositoryFile, error) {
@@ -98,6 +423,12 @@ func (r *Repository) recursiveFiles(dir string) ([]RepositoryFile, error) {
 
 	for _, file := range files {
 		fullPath := path.Join(dir, file.Name())
+
+		// Symbolic links are left out, since they aren't followed.
+		if file.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+
 		if file.Mode().IsDir() {
 			nestedFiles, nestedErr := r.recursiveFiles(fullPath)
 
@@ -130,10 +461,166 @@ func (r *Repository) recursiveFiles(dir string) ([]RepositoryFile, error) {
 	return repoFiles, err
 }
 
-func (r *Repository) Commit() {
-	panic("Unimplemented")
+var ErrNothingToCommit = errors.New("nothing to commit, working tree clean")
+var ErrDetachedHead = errors.New("HEAD is detached, a branch must be checked out to push")
+var ErrPushRejected = errors.New("push rejected by the origin remote")
+
+type CommitOptions struct {
+	AuthorName  string
+	AuthorEmail string
+	Message     string
+	// Paths that should be included in the commit.
+	// All changed files are included if no paths are provided.
+	Paths []string
 }
 
-func (r *Repository) Push() {
-	panic("Unimplemented")
+// Credentials used to authenticate against the origin remote.
+// Either the username and password (or access token), or the SSH private key should be provided.
+type Credentials struct {
+	Username         string `json:"username"`
+	Password         string `json:"password"`
+	Token            string `json:"token"`
+	SSHPrivateKey    string `json:"sshPrivateKey"`
+	SSHKeyPassphrase string `json:"sshKeyPassphrase"`
+}
+
+// Commit stages the selected paths and records a new commit on the current branch.
+func (r *Repository) Commit(options CommitOptions) (string, error) {
+	worktree, err := r.repository.Worktree()
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(options.Paths) == 0 {
+		err = worktree.AddWithOptions(&git.AddOptions{All: true})
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, filePath := range options.Paths {
+		_, err = worktree.Add(relativePath(filePath))
+
+		if err != nil {
+			return "", fmt.Errorf("failed to stage %s: %w", filePath, err)
+		}
+	}
+
+	status, err := worktree.Status()
+
+	if err != nil {
+		return "", err
+	}
+
+	if !hasStagedChanges(status) {
+		return "", ErrNothingToCommit
+	}
+
+	hash, err := worktree.Commit(options.Message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  options.AuthorName,
+			Email: options.AuthorEmail,
+			When:  time.Now(),
+		},
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	r.logger.Info().Msg(fmt.Sprintf("Created commit %s", hash))
+
+	return hash.String(), nil
+}
+
+// Push pushes the current branch to the branch with the same name on the origin remote.
+// It returns whether the remote branch was updated, which it isn't if it was already up to date.
+func (r *Repository) Push(credentials Credentials) (bool, error) {
+	head, err := r.repository.Head()
+
+	if err != nil {
+		return false, err
+	}
+
+	if !head.Name().IsBranch() {
+		return false, ErrDetachedHead
+	}
+
+	auth, err := credentials.authMethod()
+
+	if err != nil {
+		return false, err
+	}
+
+	err = r.repository.Push(&git.PushOptions{
+		Auth:     auth,
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", head.Name(), head.Name()))},
+	})
+
+	switch {
+	case errors.Is(err, git.NoErrAlreadyUpToDate):
+		return false, nil
+	case err != nil && strings.HasPrefix(err.Error(), git.ErrNonFastForwardUpdate.Error()):
+		// go-git reports rejected updates with a new error that only shares the message of ErrNonFastForwardUpdate.
+		return false, fmt.Errorf("%w: %w", ErrPushRejected, err)
+	case err != nil:
+		return false, err
+	}
+
+	r.logger.Info().Msg(fmt.Sprintf("Pushed %s", head.Name().Short()))
+
+	return true, nil
+}
+
+func (c Credentials) authMethod() (transport.AuthMethod, error) {
+	switch {
+	case c.SSHPrivateKey != "":
+		username := c.Username
+		if username == "" {
+			username = "git"
+		}
+		return ssh.NewPublicKeys(username, []byte(c.SSHPrivateKey), c.SSHKeyPassphrase)
+	case c.Token != "":
+		// Most git hosting providers accept any non-empty username with an access token.
+		username := c.Username
+		if username == "" {
+			username = "token"
+		}
+		return &http.BasicAuth{Username: username, Password: c.Token}, nil
+	case c.Username != "" || c.Password != "":
+		return &http.BasicAuth{Username: c.Username, Password: c.Password}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func hasStagedChanges(status git.Status) bool {
+	for _, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			return true
+		}
+	}
+	return false
+}
+
+// relativePath converts an absolute repository path to a path relative to the worktree root,
+// which is the format go-git expects.
+func relativePath(filePath string) string {
+	return strings.TrimPrefix(path.Clean("/"+filePath), "/")
+}
+
+// Close releases the worktree and object storage.
+func (r *Repository) Close() {
+	if closer, ok := r.storage.(io.Closer); ok {
+		err := closer.Close()
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to close repository storage")
+		}
+	}
+
+	r.repository = nil
+	r.storage = nil
+	r.fs = nil
 }
@@ -0,0 +1,610 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/rs/zerolog"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+var testLogger = zerolog.Nop()
+
+// newTestRemote creates a bare repository in a temporary directory with a single commit on the master branch,
+// which contains the given files and symbolic links (mapped to their targets), and returns its URL.
+func newTestRemote(t *testing.T, files map[string]string, symlinks map[string]string) string {
+	t.Helper()
+
+	fs := memfs.New()
+	repository, err := git.Init(memory.NewStorage(), fs)
+	if err != nil {
+		t.Fatalf("failed to init repository: %s", err)
+	}
+
+	for name, content := range files {
+		err = util.WriteFile(fs, name, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %s", name, err)
+		}
+	}
+
+	for link, target := range symlinks {
+		err = fs.Symlink(target, link)
+		if err != nil {
+			t.Fatalf("failed to create link %s: %s", link, err)
+		}
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree: %s", err)
+	}
+
+	err = worktree.AddWithOptions(&git.AddOptions{All: true})
+	if err != nil {
+		t.Fatalf("failed to stage files: %s", err)
+	}
+
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %s", err)
+	}
+
+	remoteDirectory := t.TempDir()
+	_, err = git.PlainInit(remoteDirectory, true)
+	if err != nil {
+		t.Fatalf("failed to init remote: %s", err)
+	}
+
+	url := "file://" + remoteDirectory
+
+	_, err = repository.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}})
+	if err != nil {
+		t.Fatalf("failed to add remote: %s", err)
+	}
+
+	err = repository.Push(&git.PushOptions{})
+	if err != nil {
+		t.Fatalf("failed to push to remote: %s", err)
+	}
+
+	return url
+}
+
+// testRepositories returns an in-memory and an on-disk repository, so that tests cover both filesystems.
+func testRepositories(t *testing.T) map[string]*Repository {
+	return map[string]*Repository{
+		"in memory": New(&testLogger),
+		"on disk":   NewOnDisk(&testLogger, t.TempDir()),
+	}
+}
+
+func TestSymlinksAreNotFollowed(t *testing.T) {
+	outside := t.TempDir()
+	secretPath := filepath.Join(outside, "secret.txt")
+
+	err := os.WriteFile(secretPath, []byte("secret"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write secret: %s", err)
+	}
+
+	// The relative link reaches the outside directory from the worktree of the on-disk repository.
+	directory := t.TempDir()
+	relativeOutside, err := filepath.Rel(filepath.Join(directory, "worktree"), outside)
+	if err != nil {
+		t.Fatalf("failed to get relative path: %s", err)
+	}
+
+	url := newTestRemote(t,
+		map[string]string{"main.cdc": "access(all) contract Main {}"},
+		map[string]string{"absolute": outside, "relative": relativeOutside},
+	)
+
+	repositories := map[string]*Repository{
+		"in memory": New(&testLogger),
+		"on disk":   NewOnDisk(&testLogger, directory),
+	}
+
+	for name, repository := range repositories {
+		t.Run(name, func(t *testing.T) {
+			err := repository.Clone(CloneOptions{URL: url})
+			if err != nil {
+				t.Fatalf("failed to clone: %s", err)
+			}
+			defer repository.Close()
+
+			for _, link := range []string{"/absolute", "/relative"} {
+				_, err = repository.ReadFile(link + "/secret.txt")
+				if !errors.Is(err, ErrSymlink) {
+					t.Errorf("expected reading through %s to fail with ErrSymlink, got %v", link, err)
+				}
+
+				_, err = repository.File(link)
+				if !errors.Is(err, ErrSymlink) {
+					t.Errorf("expected reading %s to fail with ErrSymlink, got %v", link, err)
+				}
+
+				err = repository.WriteFile(link+"/created.txt", []byte("created"), 0644)
+				if !errors.Is(err, ErrSymlink) {
+					t.Errorf("expected writing through %s to fail with ErrSymlink, got %v", link, err)
+				}
+
+				err = repository.MkdirAll(link+"/created", 0755)
+				if !errors.Is(err, ErrSymlink) {
+					t.Errorf("expected creating a directory through %s to fail with ErrSymlink, got %v", link, err)
+				}
+
+				err = repository.Rename("/main.cdc", link+"/main.cdc")
+				if !errors.Is(err, ErrSymlink) {
+					t.Errorf("expected renaming into %s to fail with ErrSymlink, got %v", link, err)
+				}
+
+				err = repository.Remove(link + "/secret.txt")
+				if !errors.Is(err, ErrSymlink) {
+					t.Errorf("expected removing through %s to fail with ErrSymlink, got %v", link, err)
+				}
+
+				// The filesystem itself must not reach outside the worktree either.
+				content, err := util.ReadFile(repository.fs, link+"/secret.txt")
+				if err == nil && string(content) == "secret" {
+					t.Errorf("filesystem followed %s outside the worktree", link)
+				}
+			}
+
+			if _, err := os.Stat(secretPath); err != nil {
+				t.Errorf("file outside the worktree was removed: %s", err)
+			}
+
+			entries, err := os.ReadDir(outside)
+			if err != nil {
+				t.Fatalf("failed to read outside directory: %s", err)
+			}
+
+			if len(entries) != 1 {
+				t.Errorf("files were created outside the worktree: %v", entries)
+			}
+
+			files, err := repository.Files()
+			if err != nil {
+				t.Fatalf("failed to list files: %s", err)
+			}
+
+			for _, file := range files {
+				if strings.HasPrefix(file.Path, "/absolute") || strings.HasPrefix(file.Path, "/relative") {
+					t.Errorf("listed file %s through a link", file.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestRenameSiblingsWithSharedPrefix(t *testing.T) {
+	url := newTestRemote(t, map[string]string{
+		"a/x.cdc":   "x",
+		"a/b/z.cdc": "z",
+		"ab/y.cdc":  "y",
+		"a.cdc":     "a",
+	}, nil)
+
+	for name, repository := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			err := repository.Clone(CloneOptions{URL: url})
+			if err != nil {
+				t.Fatalf("failed to clone: %s", err)
+			}
+			defer repository.Close()
+
+			tests := []struct {
+				from string
+				to   string
+				err  error
+				// files maps each path to its expected content after the rename, empty if it must not exist.
+				files map[string]string
+			}{
+				{
+					from: "/a",
+					to:   "/c",
+					files: map[string]string{
+						"/c/x.cdc":   "x",
+						"/c/b/z.cdc": "z",
+						"/ab/y.cdc":  "y",
+						"/a.cdc":     "a",
+						"/a/x.cdc":   "",
+					},
+				},
+				{
+					from: "/a.cdc",
+					to:   "/d/a.cdc",
+					files: map[string]string{
+						"/d/a.cdc":  "a",
+						"/ab/y.cdc": "y",
+						"/a.cdc":    "",
+					},
+				},
+				{
+					from: "/c",
+					to:   "/c/b/c",
+					err:  ErrMoveIntoItself,
+					files: map[string]string{
+						"/c/x.cdc": "x",
+					},
+				},
+				{
+					from: "/c",
+					to:   "/c",
+					err:  ErrMoveIntoItself,
+				},
+				{
+					from: "/ab",
+					to:   "/c",
+					err:  os.ErrExist,
+					files: map[string]string{
+						"/ab/y.cdc": "y",
+					},
+				},
+			}
+
+			for _, test := range tests {
+				err := repository.Rename(test.from, test.to)
+				if !errors.Is(err, test.err) {
+					t.Errorf("renaming %s to %s: expected error %v, got %v", test.from, test.to, test.err, err)
+				}
+
+				for filePath, expected := range test.files {
+					content, err := repository.ReadFile(filePath)
+
+					switch {
+					case expected == "" && !errors.Is(err, os.ErrNotExist):
+						t.Errorf("after renaming %s to %s: expected %s to not exist, got %v", test.from, test.to, filePath, err)
+					case expected != "" && err != nil:
+						t.Errorf("after renaming %s to %s: failed to read %s: %s", test.from, test.to, filePath, err)
+					case expected != "" && string(content) != expected:
+						t.Errorf("after renaming %s to %s: expected %s to contain %q, got %q", test.from, test.to, filePath, expected, content)
+					}
+				}
+			}
+		})
+	}
+}
+
+// remoteHead returns the commit hash of the master branch of the bare repository at the given URL.
+func remoteHead(t *testing.T, url string) string {
+	t.Helper()
+
+	remote, err := git.PlainOpen(strings.TrimPrefix(url, "file://"))
+	if err != nil {
+		t.Fatalf("failed to open remote: %s", err)
+	}
+
+	reference, err := remote.Reference(plumbing.NewBranchReferenceName("master"), true)
+	if err != nil {
+		t.Fatalf("failed to resolve remote master: %s", err)
+	}
+
+	return reference.Hash().String()
+}
+
+// headFiles returns the paths of the files in the commit at HEAD.
+func headFiles(t *testing.T, repository *Repository) map[string]bool {
+	t.Helper()
+
+	tree, err := repository.headTree()
+	if err != nil {
+		t.Fatalf("failed to get head tree: %s", err)
+	}
+
+	files := make(map[string]bool)
+	err = tree.Files().ForEach(func(file *object.File) error {
+		files[file.Name] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to list head tree: %s", err)
+	}
+
+	return files
+}
+
+func TestCommit(t *testing.T) {
+	url := newTestRemote(t, map[string]string{"main.cdc": "access(all) contract Main {}"}, nil)
+
+	for name, repository := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			err := repository.Clone(CloneOptions{URL: url})
+			if err != nil {
+				t.Fatalf("failed to clone: %s", err)
+			}
+			defer repository.Close()
+
+			// The steps run in order against the same repository.
+			tests := []struct {
+				name  string
+				write []string
+				paths []string
+				err   error
+				// committed maps each path to whether it must be part of the commit at HEAD afterwards.
+				committed map[string]bool
+			}{
+				{
+					name:      "selected paths",
+					write:     []string{"/a.cdc", "/b.cdc"},
+					paths:     []string{"/a.cdc"},
+					committed: map[string]bool{"main.cdc": true, "a.cdc": true, "b.cdc": false},
+				},
+				{
+					name:      "all changed paths",
+					committed: map[string]bool{"main.cdc": true, "a.cdc": true, "b.cdc": true},
+				},
+				{
+					name: "nothing to commit",
+					err:  ErrNothingToCommit,
+				},
+				{
+					name:  "unchanged selected path",
+					write: []string{"/c.cdc"},
+					paths: []string{"/main.cdc"},
+					err:   ErrNothingToCommit,
+				},
+			}
+
+			for _, test := range tests {
+				for _, filePath := range test.write {
+					err := repository.WriteFile(filePath, []byte(filePath), 0644)
+					if err != nil {
+						t.Fatalf("%s: failed to write %s: %s", test.name, filePath, err)
+					}
+				}
+
+				head, err := repository.Head()
+				if err != nil {
+					t.Fatalf("%s: failed to get head: %s", test.name, err)
+				}
+
+				hash, err := repository.Commit(CommitOptions{
+					AuthorName:  "Test",
+					AuthorEmail: "test@example.com",
+					Message:     test.name,
+					Paths:       test.paths,
+				})
+				if !errors.Is(err, test.err) {
+					t.Fatalf("%s: expected error %v, got %v", test.name, test.err, err)
+				}
+
+				newHead, err := repository.Head()
+				if err != nil {
+					t.Fatalf("%s: failed to get head: %s", test.name, err)
+				}
+
+				if test.err != nil {
+					if newHead != head {
+						t.Errorf("%s: expected head to stay at %s, got %s", test.name, head, newHead)
+					}
+					continue
+				}
+
+				if newHead != hash {
+					t.Errorf("%s: expected head to be the new commit %s, got %s", test.name, hash, newHead)
+				}
+
+				files := headFiles(t, repository)
+				for filePath, expected := range test.committed {
+					if files[filePath] != expected {
+						t.Errorf("%s: expected %s to be committed: %t", test.name, filePath, expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPush(t *testing.T) {
+	files := map[string]string{"main.cdc": "access(all) contract Main {}"}
+
+	commit := func(t *testing.T, repository *Repository, filePath string) string {
+		t.Helper()
+
+		err := repository.WriteFile(filePath, []byte(filePath), 0644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %s", filePath, err)
+		}
+
+		hash, err := repository.Commit(CommitOptions{AuthorName: "Test", AuthorEmail: "test@example.com", Message: filePath})
+		if err != nil {
+			t.Fatalf("failed to commit: %s", err)
+		}
+
+		return hash
+	}
+
+	tests := []struct {
+		name string
+		// prepare changes the clone and the remote before the push, and returns the expected remote head after it.
+		prepare func(t *testing.T, repository *Repository, url string) string
+		pushed  bool
+		err     error
+	}{
+		{
+			name: "new commit",
+			prepare: func(t *testing.T, repository *Repository, url string) string {
+				return commit(t, repository, "/new.cdc")
+			},
+			pushed: true,
+		},
+		{
+			name: "already up to date",
+			prepare: func(t *testing.T, repository *Repository, url string) string {
+				return remoteHead(t, url)
+			},
+		},
+		{
+			name: "detached head",
+			prepare: func(t *testing.T, repository *Repository, url string) string {
+				head, err := repository.Head()
+				if err != nil {
+					t.Fatalf("failed to get head: %s", err)
+				}
+
+				err = repository.Checkout(head, false)
+				if err != nil {
+					t.Fatalf("failed to check out %s: %s", head, err)
+				}
+
+				commit(t, repository, "/detached.cdc")
+
+				return remoteHead(t, url)
+			},
+			err: ErrDetachedHead,
+		},
+		{
+			name: "rejected",
+			prepare: func(t *testing.T, repository *Repository, url string) string {
+				other := New(&testLogger)
+				err := other.Clone(CloneOptions{URL: url})
+				if err != nil {
+					t.Fatalf("failed to clone: %s", err)
+				}
+				defer other.Close()
+
+				diverged := commit(t, other, "/other.cdc")
+
+				pushed, err := other.Push(Credentials{})
+				if err != nil || !pushed {
+					t.Fatalf("failed to push diverging commit: %t, %v", pushed, err)
+				}
+
+				commit(t, repository, "/local.cdc")
+
+				return diverged
+			},
+			err: ErrPushRejected,
+		},
+	}
+
+	for _, test := range tests {
+		for name, repository := range testRepositories(t) {
+			t.Run(test.name+"/"+name, func(t *testing.T) {
+				url := newTestRemote(t, files, nil)
+
+				err := repository.Clone(CloneOptions{URL: url})
+				if err != nil {
+					t.Fatalf("failed to clone: %s", err)
+				}
+				defer repository.Close()
+
+				expected := test.prepare(t, repository, url)
+
+				pushed, err := repository.Push(Credentials{})
+				if !errors.Is(err, test.err) {
+					t.Fatalf("expected error %v, got %v", test.err, err)
+				}
+
+				if pushed != test.pushed {
+					t.Errorf("expected pushed to be %t, got %t", test.pushed, pushed)
+				}
+
+				if head := remoteHead(t, url); head != expected {
+					t.Errorf("expected remote master at %s, got %s", expected, head)
+				}
+			})
+		}
+	}
+}
+
+func TestCredentialsAuthMethod(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	block, err := cryptossh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("failed to marshal key: %s", err)
+	}
+
+	privateKey := string(pem.EncodeToMemory(block))
+
+	tests := []struct {
+		name        string
+		credentials Credentials
+		expected    transport.AuthMethod
+		err         bool
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:        "username and password",
+			credentials: Credentials{Username: "user", Password: "secret"},
+			expected:    &http.BasicAuth{Username: "user", Password: "secret"},
+		},
+		{
+			name:        "token",
+			credentials: Credentials{Token: "abc"},
+			expected:    &http.BasicAuth{Username: "token", Password: "abc"},
+		},
+		{
+			name:        "token with username",
+			credentials: Credentials{Username: "user", Token: "abc"},
+			expected:    &http.BasicAuth{Username: "user", Password: "abc"},
+		},
+		{
+			name:        "ssh key",
+			credentials: Credentials{SSHPrivateKey: privateKey},
+			expected:    &ssh.PublicKeys{User: "git"},
+		},
+		{
+			name:        "ssh key with username",
+			credentials: Credentials{Username: "deploy", SSHPrivateKey: privateKey},
+			expected:    &ssh.PublicKeys{User: "deploy"},
+		},
+		{
+			name:        "invalid ssh key",
+			credentials: Credentials{SSHPrivateKey: "not a key"},
+			err:         true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth, err := test.credentials.authMethod()
+			if (err != nil) != test.err {
+				t.Fatalf("expected error: %t, got %v", test.err, err)
+			}
+
+			switch expected := test.expected.(type) {
+			case nil:
+				if auth != nil && !test.err {
+					t.Errorf("expected no authentication, got %s", auth)
+				}
+			case *http.BasicAuth:
+				basic, ok := auth.(*http.BasicAuth)
+				if !ok || *basic != *expected {
+					t.Errorf("expected %+v, got %+v", expected, auth)
+				}
+			case *ssh.PublicKeys:
+				keys, ok := auth.(*ssh.PublicKeys)
+				if !ok || keys.User != expected.User || keys.Signer == nil {
+					t.Errorf("expected public keys for %s, got %+v", expected.User, auth)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,776 @@
+package project
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flow-go-sdk/templates"
+	flowgo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flowkit"
+	"github.com/onflow/flowkit/accounts"
+	"github.com/onflow/flowkit/config"
+	configjson "github.com/onflow/flowkit/config/json"
+	"github.com/onflow/flowkit/transactions"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrInvalidAddress  = errors.New("invalid address")
+	ErrInvalidPath     = errors.New("invalid storage path")
+	// ErrStorageValueNotFound is returned if nothing is stored or linked at a path.
+	ErrStorageValueNotFound = errors.New("storage value not found")
+	ErrInvalidAccount       = errors.New("invalid account")
+	ErrAccountExists        = errors.New("account already exists")
+	// ErrAccountNotSignable is returned if the account isn't in flow.json, so its keys are unknown.
+	ErrAccountNotSignable = errors.New("account can't sign transactions")
+)
+
+type Account struct {
+	Address string `json:"address"`
+	// Name is the name of the flow.json account with this address, empty if there is none.
+	Name string `json:"name,omitempty"`
+	// Balance is the amount of FLOW held by the account.
+	Balance   string            `json:"balance"`
+	Keys      []AccountKey      `json:"keys"`
+	Contracts []AccountContract `json:"contracts"`
+}
+
+type AccountKey struct {
+	Index          int    `json:"index"`
+	PublicKey      string `json:"publicKey"`
+	SigAlgo        string `json:"sigAlgo"`
+	HashAlgo       string `json:"hashAlgo"`
+	Weight         int    `json:"weight"`
+	SequenceNumber uint64 `json:"sequenceNumber"`
+	Revoked        bool   `json:"revoked"`
+}
+
+type AccountContract struct {
+	Name string `json:"name"`
+	// Code is only included when a single account is requested.
+	Code string `json:"code,omitempty"`
+}
+
+type StorageItem struct {
+	// Path is the full path, e.g. "/storage/flowTokenVault".
+	Path string `json:"path"`
+	// Domain is "storage", "public" or "private".
+	Domain string `json:"domain"`
+	// Type is the Cadence type identifier of the stored value, or of the capability linked at public and private paths.
+	Type string `json:"type"`
+}
+
+type StorageValue struct {
+	StorageItem
+	// Value is encoded as JSON-Cadence. Resources are read through a reference,
+	// public and private paths hold the linked capability.
+	Value json.RawMessage `json:"value"`
+	// SimplifiedValue is the value as plain JSON, only set if requested.
+	SimplifiedValue any `json:"simplifiedValue,omitempty"`
+}
+
+// Accounts returns all accounts created on the project chain, in the order they were created.
+// Contract code is omitted.
+func (p *Project) Accounts() ([]Account, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	addresses, err := p.accountAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Account, 0, len(addresses))
+	for _, address := range addresses {
+		account, err := p.account(address, false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *account)
+	}
+
+	return result, nil
+}
+
+// Account returns the account with the given address, including the code of its contracts.
+func (p *Project) Account(address string) (*Account, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	flowAddress, err := p.existingAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.account(flowAddress, true)
+}
+
+// AccountStorage returns the paths of the values stored in the account and the capabilities it links.
+func (p *Project) AccountStorage(address string) ([]StorageItem, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	flowAddress, err := p.existingAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.kit.ExecuteScript(
+		context.Background(),
+		flowkit.Script{Code: []byte(storagePathsScript), Args: []cadence.Value{cadence.NewAddress(flowAddress)}},
+		flowkit.LatestScriptQuery,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]StorageItem, 0)
+
+	array, ok := value.(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("unexpected storage paths result %s", value)
+	}
+
+	for _, element := range array.Values {
+		dictionary, ok := element.(cadence.Dictionary)
+		if !ok {
+			return nil, fmt.Errorf("unexpected storage path %s", element)
+		}
+
+		fields := make(map[string]string, len(dictionary.Pairs))
+		for _, pair := range dictionary.Pairs {
+			key, _ := pair.Key.(cadence.String)
+			value, _ := pair.Value.(cadence.String)
+			fields[string(key)] = string(value)
+		}
+
+		domain, _, _ := strings.Cut(strings.TrimPrefix(fields["path"], "/"), "/")
+		items = append(items, StorageItem{
+			Path:   fields["path"],
+			Domain: domain,
+			Type:   fields["type"],
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Path < items[j].Path
+	})
+
+	return items, nil
+}
+
+// AccountStorageValue returns the value stored at the path of the account, e.g. "/storage/flowTokenVault".
+func (p *Project) AccountStorageValue(address string, path string, simplified bool) (*StorageValue, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	flowAddress, err := p.existingAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	domainName, identifier, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	domain := common.PathDomainFromIdentifier(domainName)
+	if !ok || identifier == "" || strings.Contains(identifier, "/") || domain == common.PathDomainUnknown {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+	}
+	path = "/" + domain.Identifier() + "/" + identifier
+
+	script := storageValueScript
+	switch domain {
+	case common.PathDomainPublic:
+		script = fmt.Sprintf(linkedValueScript, "PublicPath")
+	case common.PathDomainPrivate:
+		script = fmt.Sprintf(linkedValueScript, "PrivatePath")
+	}
+
+	value, err := p.kit.ExecuteScript(
+		context.Background(),
+		flowkit.Script{
+			Code: []byte(script),
+			Args: []cadence.Value{
+				cadence.NewAddress(flowAddress),
+				cadence.Path{Domain: domain, Identifier: identifier},
+			},
+		},
+		flowkit.LatestScriptQuery,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	optional, ok := value.(cadence.Optional)
+	if !ok || optional.Value == nil {
+		return nil, fmt.Errorf("%w: %s in account 0x%s", ErrStorageValueNotFound, path, flowAddress)
+	}
+
+	encoded, err := jsoncdc.Encode(optional.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StorageValue{
+		StorageItem: StorageItem{
+			Path:   path,
+			Domain: domain.Identifier(),
+			Type:   optional.Value.Type().ID(),
+		},
+		Value: encoded,
+	}
+
+	if simplified {
+		result.SimplifiedValue = simpleValue(optional.Value)
+	}
+
+	return result, nil
+}
+
+// accountAddresses returns the addresses of all accounts created on the project chain, in the order they were created.
+// Addresses are generated sequentially, so they are derived from the number of addresses generated so far,
+// which includes the system accounts created at genesis.
+func (p *Project) accountAddresses() ([]flow.Address, error) {
+	store := p.blockchain.Storage()
+
+	height, err := store.LatestBlockHeight(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := store.LedgerByHeight(context.Background(), height)
+	if err != nil {
+		return nil, err
+	}
+
+	generatorState, err := ledger.Get(flowgo.AddressStateRegisterID)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := flowgo.Emulator.Chain()
+	count := chain.BytesToAddressGenerator(generatorState).AddressCount()
+	addresses := make([]flow.Address, 0, count)
+
+	for index := uint64(1); index <= count; index++ {
+		address, err := chain.AddressAtIndex(index)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, flow.Address(address))
+	}
+
+	return addresses, nil
+}
+
+// existingAddress parses the address and checks that an account with the address was created on the project chain.
+func (p *Project) existingAddress(address string) (flow.Address, error) {
+	flowAddress, ok := parseAddress(address)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+
+	addresses, err := p.accountAddresses()
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+
+	for _, existing := range addresses {
+		if existing == flowAddress {
+			return flowAddress, nil
+		}
+	}
+
+	return flow.EmptyAddress, fmt.Errorf("%w: 0x%s", ErrAccountNotFound, flowAddress)
+}
+
+func (p *Project) account(address flow.Address, includeCode bool) (*Account, error) {
+	flowAccount, err := p.kit.Gateway().GetAccount(context.Background(), address)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		Address:   "0x" + address.Hex(),
+		Balance:   cadence.UFix64(flowAccount.Balance).String(),
+		Keys:      make([]AccountKey, 0, len(flowAccount.Keys)),
+		Contracts: make([]AccountContract, 0, len(flowAccount.Contracts)),
+	}
+
+	state, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, configured := range *state.Accounts() {
+		if configured.Address == address {
+			account.Name = configured.Name
+		}
+	}
+
+	for _, key := range flowAccount.Keys {
+		account.Keys = append(account.Keys, AccountKey{
+			Index:          key.Index,
+			PublicKey:      key.PublicKey.String(),
+			SigAlgo:        key.SigAlgo.String(),
+			HashAlgo:       key.HashAlgo.String(),
+			Weight:         key.Weight,
+			SequenceNumber: key.SequenceNumber,
+			Revoked:        key.Revoked,
+		})
+	}
+
+	for name, code := range flowAccount.Contracts {
+		contract := AccountContract{Name: name}
+		if includeCode {
+			contract.Code = string(code)
+		}
+		account.Contracts = append(account.Contracts, contract)
+	}
+
+	sort.Slice(account.Contracts, func(i, j int) bool {
+		return account.Contracts[i].Name < account.Contracts[j].Name
+	})
+
+	return account, nil
+}
+
+// parseAddress parses a hex encoded address, with or without the 0x prefix.
+// Short addresses (e.g. "0x01") are padded to the full address length.
+func parseAddress(address string) (flow.Address, bool) {
+	digits := strings.TrimPrefix(address, "0x")
+	if digits == "" || len(digits) > 2*flow.AddressLength {
+		return flow.EmptyAddress, false
+	}
+
+	if _, err := hex.DecodeString(strings.Repeat("0", len(digits)%2) + digits); err != nil {
+		return flow.EmptyAddress, false
+	}
+
+	return flow.HexToAddress(digits), true
+}
+
+// storagePathsScript lists the storage, public and private paths of an account with the types stored at them.
+const storagePathsScript = `
+pub fun main(address: Address): [{String: String}] {
+    let account = getAuthAccount(address)
+    let paths: [{String: String}] = []
+
+    account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+        paths.append({"path": path.toString(), "type": type.identifier})
+        return true
+    })
+
+    account.forEachPublic(fun (path: PublicPath, type: Type): Bool {
+        paths.append({"path": path.toString(), "type": type.identifier})
+        return true
+    })
+
+    account.forEachPrivate(fun (path: PrivatePath, type: Type): Bool {
+        paths.append({"path": path.toString(), "type": type.identifier})
+        return true
+    })
+
+    return paths
+}
+`
+
+// storageValueScript reads the value stored at a storage path,
+// resources are borrowed because they can't be copied.
+const storageValueScript = `
+pub fun main(address: Address, path: StoragePath): AnyStruct? {
+    let account = getAuthAccount(address)
+    let type = account.type(at: path)
+    if type == nil {
+        return nil
+    }
+
+    if type!.isSubtype(of: Type<@AnyResource>()) {
+        return account.borrow<&AnyResource>(from: path)
+    }
+
+    return account.copy<AnyStruct>(from: path)
+}
+`
+
+// linkedValueScript reads the capability linked at a public or private path.
+const linkedValueScript = `
+pub fun main(address: Address, path: %s): AnyStruct? {
+    let account = getAuthAccount(address)
+    if account.getLinkTarget(path) == nil {
+        return nil
+    }
+
+    return account.getCapability(path)
+}
+`
+
+type CreateAccountOptions struct {
+	// Name of the account in flow.json, which is used to sign transactions with the account.
+	Name string
+	// PrivateKey is the hex encoded private key of the account, a key is generated if empty.
+	PrivateKey string
+	// SigAlgo and HashAlgo of the account key, ECDSA_P256 and SHA3_256 are used if empty.
+	SigAlgo  string
+	HashAlgo string
+	// Balance is the amount of FLOW transferred to the account from the service account, the account is not funded if empty.
+	Balance string
+	// Save adds the account to flow.json in the repository, otherwise it only exists until the project is closed.
+	Save bool
+}
+
+type CreatedAccount struct {
+	Account
+	// PrivateKey is the hex encoded private key that transactions are signed with for the account.
+	PrivateKey string `json:"privateKey"`
+	// FundingError is set if the account was created, but transferring the balance to it failed.
+	FundingError string `json:"fundingError,omitempty"`
+}
+
+type AccountKeyOptions struct {
+	// PublicKey is the hex encoded public key.
+	PublicKey string
+	// SigAlgo and HashAlgo of the key, ECDSA_P256 and SHA3_256 are used if empty.
+	SigAlgo  string
+	HashAlgo string
+	// Weight of the key, a key with full weight is added if zero.
+	Weight int
+}
+
+// CreateAccount creates a new account with a single full weight key, which is added to the flow.json accounts.
+// If the account can't be funded, it is returned with the funding error.
+func (p *Project) CreateAccount(options CreateAccountOptions) (*CreatedAccount, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	if options.Name == "" {
+		return nil, fmt.Errorf("%w: missing name", ErrInvalidAccount)
+	}
+
+	if options.Balance != "" {
+		if _, err := parseFlowAmount(options.Balance); err != nil {
+			return nil, fmt.Errorf("%w: invalid balance %s", ErrInvalidAccount, options.Balance)
+		}
+	}
+
+	state, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := state.Accounts().ByName(options.Name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAccountExists, options.Name)
+	}
+
+	sigAlgo, hashAlgo, err := keyAlgorithms(options.SigAlgo, options.HashAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	var privateKey crypto.PrivateKey
+	if options.PrivateKey == "" {
+		privateKey, err = p.kit.GenerateKey(context.Background(), sigAlgo, "")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		privateKey, err = crypto.DecodePrivateKeyHex(sigAlgo, strings.TrimPrefix(options.PrivateKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid private key: %s", ErrInvalidAccount, err)
+		}
+	}
+
+	serviceAccount, err := state.EmulatorServiceAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	created, _, err := p.kit.CreateAccount(
+		context.Background(),
+		serviceAccount,
+		[]accounts.PublicKey{{
+			Public:   privateKey.PublicKey(),
+			Weight:   flow.AccountKeyWeightThreshold,
+			SigAlgo:  sigAlgo,
+			HashAlgo: hashAlgo,
+		}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Funding must happen before the accounts are updated, which invalidates the service account reference.
+	// The account exists on the chain even if funding fails, so it's still added and the error is reported with it.
+	var fundingErr error
+	if options.Balance != "" {
+		fundingErr = p.fundAccount(serviceAccount, created.Address, options.Balance)
+	}
+
+	account := &accounts.Account{
+		Name:    options.Name,
+		Address: created.Address,
+		Key:     accounts.NewHexKeyFromPrivateKey(0, hashAlgo, privateKey),
+	}
+	state.Accounts().AddOrUpdate(account)
+
+	if options.Save {
+		err = p.saveConfigAccount(account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.saveState()
+
+	result, err := p.account(created.Address, false)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAccount := &CreatedAccount{
+		Account:    *result,
+		PrivateKey: hex.EncodeToString(privateKey.Encode()),
+	}
+
+	if fundingErr != nil {
+		createdAccount.FundingError = fundingErr.Error()
+	}
+
+	return createdAccount, nil
+}
+
+// FundAccount transfers the amount of FLOW from the service account to the account.
+func (p *Project) FundAccount(address string, amount string) (*Account, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	flowAddress, err := p.existingAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := parseFlowAmount(amount); err != nil {
+		return nil, fmt.Errorf("%w: invalid amount %s", ErrInvalidAccount, amount)
+	}
+
+	serviceAccount, err := p.accountByName("")
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.fundAccount(serviceAccount, flowAddress, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.account(flowAddress, false)
+}
+
+// AddAccountKey adds a public key to the account, the transaction is signed by the account's flow.json key.
+func (p *Project) AddAccountKey(address string, options AccountKeyOptions) (*Account, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	signer, err := p.accountSigner(address)
+	if err != nil {
+		return nil, err
+	}
+
+	sigAlgo, hashAlgo, err := keyAlgorithms(options.SigAlgo, options.HashAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := crypto.DecodePublicKeyHex(sigAlgo, strings.TrimPrefix(options.PublicKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid public key: %s", ErrInvalidAccount, err)
+	}
+
+	weight := options.Weight
+	if weight == 0 {
+		weight = flow.AccountKeyWeightThreshold
+	}
+
+	tx, err := templates.AddAccountKey(signer.Address, &flow.AccountKey{
+		PublicKey: publicKey,
+		SigAlgo:   sigAlgo,
+		HashAlgo:  hashAlgo,
+		Weight:    weight,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAccount, err)
+	}
+
+	err = p.sendAccountTransaction(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.account(signer.Address, false)
+}
+
+// RevokeAccountKey revokes the key with the given index, the transaction is signed by the account's flow.json key,
+// which therefore can't be revoked.
+func (p *Project) RevokeAccountKey(address string, keyIndex int) (*Account, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	signer, err := p.accountSigner(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyIndex == signer.Key.Index() {
+		return nil, fmt.Errorf("%w: key %d is used to sign transactions for the account", ErrInvalidAccount, keyIndex)
+	}
+
+	flowAccount, err := p.kit.Gateway().GetAccount(context.Background(), signer.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyIndex < 0 || keyIndex >= len(flowAccount.Keys) {
+		return nil, fmt.Errorf("%w: account has no key %d", ErrInvalidAccount, keyIndex)
+	}
+
+	err = p.sendAccountTransaction(signer, templates.RemoveAccountKey(signer.Address, keyIndex))
+	if err != nil {
+		return nil, err
+	}
+
+	return p.account(signer.Address, false)
+}
+
+// accountSigner returns the flow.json account with the given address, which is used to sign transactions for it.
+func (p *Project) accountSigner(address string) (*accounts.Account, error) {
+	flowAddress, err := p.existingAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range *state.Accounts() {
+		if account.Address == flowAddress {
+			return &account, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: 0x%s", ErrAccountNotSignable, flowAddress)
+}
+
+// sendAccountTransaction sends a transaction built from an SDK template, signed by the account.
+func (p *Project) sendAccountTransaction(signer *accounts.Account, tx *flow.Transaction) error {
+	args := make([]cadence.Value, 0, len(tx.Arguments))
+	for _, argument := range tx.Arguments {
+		value, err := jsoncdc.Decode(nil, argument)
+		if err != nil {
+			return err
+		}
+		args = append(args, value)
+	}
+
+	_, result, err := p.kit.SendTransaction(
+		context.Background(),
+		transactions.SingleAccountRole(*signer),
+		flowkit.Script{Code: tx.Script, Args: args},
+		flow.DefaultTransactionGasLimit,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update account %s: %w", signer.Address, result.Error)
+	}
+
+	return nil
+}
+
+// saveConfigAccount adds the account to flow.json in the repository.
+// The configuration is loaded from the file, as the flowkit state holds the addresses of the created accounts.
+func (p *Project) saveConfigAccount(account *accounts.Account) error {
+	loader := config.NewLoader(p.repository.Directory(p.directory))
+	loader.AddConfigParser(configjson.NewParser())
+
+	conf, err := loader.Load([]string{configFileName})
+	if err != nil {
+		return err
+	}
+
+	conf.Accounts.AddOrUpdate(account.Name, accounts.ToConfig(accounts.Accounts{*account})[0])
+
+	return loader.Save(conf, configFileName)
+}
+
+// parseFlowAmount parses an amount of FLOW, which may omit the fractional part (e.g. "10").
+func parseFlowAmount(amount string) (cadence.UFix64, error) {
+	if !strings.Contains(amount, ".") {
+		amount += ".0"
+	}
+	return cadence.NewUFix64(amount)
+}
+
+func keyAlgorithms(sigAlgoName string, hashAlgoName string) (crypto.SignatureAlgorithm, crypto.HashAlgorithm, error) {
+	sigAlgo := crypto.ECDSA_P256
+	if sigAlgoName != "" {
+		sigAlgo = crypto.StringToSignatureAlgorithm(sigAlgoName)
+	}
+
+	hashAlgo := crypto.SHA3_256
+	if hashAlgoName != "" {
+		hashAlgo = crypto.StringToHashAlgorithm(hashAlgoName)
+	}
+
+	if sigAlgo == crypto.UnknownSignatureAlgorithm {
+		return sigAlgo, hashAlgo, fmt.Errorf("%w: unknown signature algorithm %s", ErrInvalidAccount, sigAlgoName)
+	}
+
+	if hashAlgo == crypto.UnknownHashAlgorithm {
+		return sigAlgo, hashAlgo, fmt.Errorf("%w: unknown hash algorithm %s", ErrInvalidAccount, hashAlgoName)
+	}
+
+	return sigAlgo, hashAlgo, nil
+}
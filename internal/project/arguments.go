@@ -0,0 +1,392 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/flowkit/arguments"
+	"strings"
+)
+
+var ErrInvalidArguments = errors.New("invalid arguments")
+
+type ProgramKind string
+
+const (
+	ProgramScript      ProgramKind = "script"
+	ProgramTransaction ProgramKind = "transaction"
+)
+
+type Parameter struct {
+	Name string `json:"name"`
+	// Type is the Cadence type of the parameter, e.g. "UFix64" or "[Address]".
+	Type string `json:"type"`
+}
+
+// ProgramParameters describes the arguments that a script or transaction must be executed with.
+type ProgramParameters struct {
+	Kind       ProgramKind `json:"kind"`
+	Parameters []Parameter `json:"parameters"`
+	// Authorizers is the number of accounts that must authorize the transaction, zero for scripts.
+	Authorizers int `json:"authorizers"`
+}
+
+// ArgumentError describes why the value of an argument couldn't be converted to its parameter type.
+type ArgumentError struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+type ArgumentsError struct {
+	Errors []ArgumentError
+}
+
+func (e *ArgumentsError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, argumentError := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", argumentError.Name, argumentError.Message))
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidArguments, strings.Join(messages, ", "))
+}
+
+func (e *ArgumentsError) Unwrap() error {
+	return ErrInvalidArguments
+}
+
+// Parameters returns the parameters of the given script or transaction source.
+func (p *Project) Parameters(code []byte, location string) (*ProgramParameters, error) {
+	return programParameters(code, location)
+}
+
+// FileParameters returns the parameters of the script or transaction stored at the given repository path.
+func (p *Project) FileParameters(filePath string) (*ProgramParameters, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	code, err := p.repository.ReadFile(cleanFilePath(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	return programParameters(code, filePath)
+}
+
+func programParameters(code []byte, location string) (*ProgramParameters, error) {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		cadenceError := newCadenceError(err, nil)
+		if location != "" {
+			cadenceError.Location = cleanFilePath(location)
+		}
+		return nil, cadenceError
+	}
+
+	result := &ProgramParameters{
+		Parameters: make([]Parameter, 0),
+	}
+
+	var parameterList *ast.ParameterList
+
+	if transactions := program.TransactionDeclarations(); len(transactions) > 0 {
+		result.Kind = ProgramTransaction
+		parameterList = transactions[0].ParameterList
+		result.Authorizers, _ = prepareParameterCount(code, location)
+	} else {
+		for _, function := range program.FunctionDeclarations() {
+			if function.Identifier.Identifier == "main" {
+				result.Kind = ProgramScript
+				parameterList = function.ParameterList
+			}
+		}
+	}
+
+	if result.Kind == "" {
+		return nil, fmt.Errorf("%w: code must declare a transaction or a main function", ErrInvalidArguments)
+	}
+
+	if parameterList == nil {
+		return result, nil
+	}
+
+	for _, parameter := range parameterList.Parameters {
+		result.Parameters = append(result.Parameters, Parameter{
+			Name: parameter.Identifier.Identifier,
+			Type: parameter.TypeAnnotation.Type.String(),
+		})
+	}
+
+	return result, nil
+}
+
+// parseArguments converts the arguments to Cadence values.
+// Arguments are either a JSON-Cadence encoded array, or simplified arguments keyed by parameter name,
+// which are converted using the parameter types declared by the code.
+func parseArguments(code []byte, argsJson string, namedArguments map[string]json.RawMessage) ([]cadence.Value, error) {
+	if namedArguments == nil {
+		if argsJson == "" {
+			return nil, nil
+		}
+		return arguments.ParseJSON(argsJson)
+	}
+
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		// The syntax error is reported when the program is executed.
+		return nil, nil
+	}
+
+	var parameterList *ast.ParameterList
+	if transactions := program.TransactionDeclarations(); len(transactions) > 0 {
+		parameterList = transactions[0].ParameterList
+	}
+	for _, function := range program.FunctionDeclarations() {
+		if function.Identifier.Identifier == "main" {
+			parameterList = function.ParameterList
+		}
+	}
+
+	parameters := make([]*ast.Parameter, 0)
+	if parameterList != nil {
+		parameters = parameterList.Parameters
+	}
+
+	values := make([]cadence.Value, 0, len(parameters))
+	argumentErrors := make([]ArgumentError, 0)
+	known := make(map[string]bool)
+
+	for _, parameter := range parameters {
+		name := parameter.Identifier.Identifier
+		known[name] = true
+
+		argument, ok := namedArguments[name]
+		if !ok {
+			argumentErrors = append(argumentErrors, ArgumentError{Name: name, Message: "missing argument"})
+			continue
+		}
+
+		value, err := simpleArgument(parameter.TypeAnnotation.Type, argument)
+		if err != nil {
+			argumentErrors = append(argumentErrors, ArgumentError{Name: name, Message: err.Error()})
+			continue
+		}
+
+		values = append(values, value)
+	}
+
+	for name := range namedArguments {
+		if !known[name] {
+			argumentErrors = append(argumentErrors, ArgumentError{Name: name, Message: "unknown argument"})
+		}
+	}
+
+	if len(argumentErrors) > 0 {
+		return nil, &ArgumentsError{Errors: argumentErrors}
+	}
+
+	return values, nil
+}
+
+// simpleArgument converts a plain JSON value to a Cadence value of the given type,
+// by encoding it as JSON-Cadence first.
+func simpleArgument(argumentType ast.Type, argument json.RawMessage) (cadence.Value, error) {
+	encoded, err := jsonCadenceValue(argumentType, argument)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedJson, err := json.Marshal(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := jsoncdc.Decode(nil, encodedJson)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", argumentType, err)
+	}
+
+	return value, nil
+}
+
+type jsonCadence struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+type jsonCadencePair struct {
+	Key   any `json:"key"`
+	Value any `json:"value"`
+}
+
+func jsonCadenceValue(argumentType ast.Type, argument json.RawMessage) (any, error) {
+	switch t := argumentType.(type) {
+	case *ast.OptionalType:
+		if isNull(argument) {
+			return jsonCadence{Type: "Optional", Value: nil}, nil
+		}
+
+		value, err := jsonCadenceValue(t.Type, argument)
+		if err != nil {
+			return nil, err
+		}
+
+		return jsonCadence{Type: "Optional", Value: value}, nil
+
+	case *ast.VariableSizedType:
+		return jsonCadenceArray(t.Type, argument)
+
+	case *ast.ConstantSizedType:
+		return jsonCadenceArray(t.Type, argument)
+
+	case *ast.DictionaryType:
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(argument, &entries); err != nil {
+			return nil, fmt.Errorf("expected an object for %s", argumentType)
+		}
+
+		pairs := make([]jsonCadencePair, 0, len(entries))
+		for key, entry := range entries {
+			encodedKey, err := json.Marshal(key)
+			if err != nil {
+				return nil, err
+			}
+
+			keyValue, err := jsonCadenceValue(t.KeyType, encodedKey)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+
+			value, err := jsonCadenceValue(t.ValueType, entry)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+
+			pairs = append(pairs, jsonCadencePair{Key: keyValue, Value: value})
+		}
+
+		return jsonCadence{Type: "Dictionary", Value: pairs}, nil
+
+	case *ast.NominalType:
+		return jsonCadenceSimpleValue(t.String(), argument)
+
+	default:
+		return nil, fmt.Errorf("type %s is not supported for simplified arguments, use JSON-Cadence arguments", argumentType)
+	}
+}
+
+func jsonCadenceArray(elementType ast.Type, argument json.RawMessage) (any, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(argument, &elements); err != nil {
+		return nil, fmt.Errorf("expected an array of %s", elementType)
+	}
+
+	values := make([]any, 0, len(elements))
+	for i, element := range elements {
+		value, err := jsonCadenceValue(elementType, element)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		values = append(values, value)
+	}
+
+	return jsonCadence{Type: "Array", Value: values}, nil
+}
+
+func jsonCadenceSimpleValue(typeName string, argument json.RawMessage) (any, error) {
+	switch {
+	case typeName == "String" || typeName == "Character":
+		var value string
+		if err := json.Unmarshal(argument, &value); err != nil {
+			return nil, fmt.Errorf("expected a string for %s", typeName)
+		}
+		return jsonCadence{Type: typeName, Value: value}, nil
+
+	case typeName == "Bool":
+		var value bool
+		if err := json.Unmarshal(argument, &value); err != nil {
+			return nil, fmt.Errorf("expected a boolean for %s", typeName)
+		}
+		return jsonCadence{Type: typeName, Value: value}, nil
+
+	case typeName == "Address":
+		value, err := stringOrNumber(argument)
+		if err != nil {
+			return nil, fmt.Errorf("expected a hex string for %s", typeName)
+		}
+		address, ok := parseAddress(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid address %s", value)
+		}
+		return jsonCadence{Type: typeName, Value: "0x" + address.Hex()}, nil
+
+	case typeName == "Fix64" || typeName == "UFix64":
+		value, err := stringOrNumber(argument)
+		if err != nil {
+			return nil, fmt.Errorf("expected a decimal number for %s", typeName)
+		}
+		// Fixed point numbers must have a fractional part in JSON-Cadence.
+		if !strings.Contains(value, ".") {
+			value += ".0"
+		}
+		return jsonCadence{Type: typeName, Value: value}, nil
+
+	case isIntegerType(typeName):
+		value, err := stringOrNumber(argument)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer for %s", typeName)
+		}
+		return jsonCadence{Type: typeName, Value: value}, nil
+
+	case strings.HasSuffix(typeName, "Path"):
+		var value string
+		if err := json.Unmarshal(argument, &value); err != nil {
+			return nil, fmt.Errorf("expected a path string (e.g. \"/storage/foo\") for %s", typeName)
+		}
+		domain, identifier, ok := strings.Cut(strings.TrimPrefix(value, "/"), "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid path %s", value)
+		}
+		return jsonCadence{Type: "Path", Value: map[string]string{"domain": domain, "identifier": identifier}}, nil
+
+	default:
+		return nil, fmt.Errorf("type %s is not supported for simplified arguments, use JSON-Cadence arguments", typeName)
+	}
+}
+
+func isIntegerType(typeName string) bool {
+	for _, prefix := range []string{"Int", "UInt", "Word"} {
+		if typeName == prefix || strings.HasPrefix(typeName, prefix) && strings.Trim(typeName[len(prefix):], "0123456789") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// stringOrNumber returns the text of a JSON string or number.
+func stringOrNumber(argument json.RawMessage) (string, error) {
+	var value string
+	if err := json.Unmarshal(argument, &value); err == nil {
+		return value, nil
+	}
+
+	var number json.Number
+	decoder := json.NewDecoder(bytes.NewReader(argument))
+	decoder.UseNumber()
+	if err := decoder.Decode(&number); err != nil {
+		return "", err
+	}
+
+	return number.String(), nil
+}
+
+func isNull(argument json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(argument), []byte("null"))
+}
@@ -0,0 +1,256 @@
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fri-flowser-playground/internal/git"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var ErrInvalidBundle = errors.New("invalid project bundle")
+
+const bundleVersion = 1
+
+// maxBundleContentSize is the maximum total size of the decompressed bundle entries.
+const maxBundleContentSize = 512 << 20
+
+// Project bundles are gzipped tar archives with the following entries:
+//   - bundle.json: the project state, including the created accounts and their keys, and the git references,
+//   - repository/objects.pack and repository/config: the git objects and configuration,
+//   - worktree/...: the worktree files, including uncommitted changes,
+//   - chain.journal: the emulator blocks.
+const (
+	bundleManifestName = "bundle.json"
+	bundleObjectsName  = "repository/objects.pack"
+	bundleConfigName   = "repository/config"
+	bundleWorktreeDir  = "worktree/"
+	bundleChainName    = "chain.journal"
+)
+
+type bundleManifest struct {
+	Version    int                   `json:"version"`
+	Project    persistedState        `json:"project"`
+	References []git.BundleReference `json:"references"`
+	Shallow    []string              `json:"shallow"`
+	Depth      int                   `json:"depth"`
+}
+
+// Export writes the project bundle, which can be imported as a new project.
+func (p *Project) Export(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	state, err := p.persistedState()
+	if err != nil {
+		return err
+	}
+
+	// The bundle only includes the current chain state, so snapshots can't be imported.
+	state.Snapshots = nil
+
+	repositoryBundle, err := p.repository.Bundle()
+	if err != nil {
+		return err
+	}
+
+	var chain bytes.Buffer
+	err = p.blockchain.Export(&chain)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := json.MarshalIndent(bundleManifest{
+		Version:    bundleVersion,
+		Project:    *state,
+		References: repositoryBundle.References,
+		Shallow:    repositoryBundle.Shallow,
+		Depth:      repositoryBundle.Depth,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	entries := map[string][]byte{
+		bundleManifestName: manifest,
+		bundleObjectsName:  repositoryBundle.Objects,
+		bundleConfigName:   repositoryBundle.Config,
+		bundleChainName:    chain.Bytes(),
+	}
+	for filePath, content := range repositoryBundle.Files {
+		entries[bundleWorktreeDir+strings.TrimPrefix(filePath, "/")] = content
+	}
+
+	now := time.Now()
+	for name, content := range entries {
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tarWriter.Write(content)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+// Import opens the project from a bundle written by Export, instead of cloning its repository.
+// Contracts aren't redeployed, since the bundle includes the emulator state.
+func (p *Project) Import(r io.Reader) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	entries, err := readBundle(r)
+	if err != nil {
+		return err
+	}
+
+	var manifest bundleManifest
+	err = json.Unmarshal(entries[bundleManifestName], &manifest)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+
+	if manifest.Version != bundleVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidBundle, manifest.Version)
+	}
+
+	files := make(map[string][]byte)
+	for name, content := range entries {
+		if strings.HasPrefix(name, bundleWorktreeDir) {
+			files[path.Join("/", strings.TrimPrefix(name, bundleWorktreeDir))] = content
+		}
+	}
+
+	state := manifest.Project
+	p.url = state.ProjectUrl
+	p.ref = state.Ref
+	p.directory = state.Directory
+	p.autoDeploy = state.AutoDeploy
+	p.lastDeployment = state.LastDeployment
+	p.blockchainOptions.TransactionFees = state.TransactionFees
+	p.blockchainOptions.StorageLimit = state.StorageLimit
+	p.blockchainOptions.AccessAPI = state.AccessAPI
+
+	p.logger.Info().Msg(fmt.Sprintf("Importing project: %s", state.ProjectUrl))
+
+	err = p.repository.Import(&git.Bundle{
+		Objects:    entries[bundleObjectsName],
+		Config:     entries[bundleConfigName],
+		References: manifest.References,
+		Shallow:    manifest.Shallow,
+		Depth:      manifest.Depth,
+		Files:      files,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+
+	err = p.blockchain.Import(bytes.NewReader(entries[bundleChainName]))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+
+	err = p.blockchain.Start(p.blockchainOptions)
+	if err != nil {
+		return err
+	}
+
+	kit, err := p.initFlowKit()
+	if err != nil {
+		return err
+	}
+
+	p.kit = kit
+
+	err = p.restoreAccounts(state.Accounts)
+	if err != nil {
+		return err
+	}
+
+	p.saveState()
+
+	return nil
+}
+
+func readBundle(r io.Reader) (map[string][]byte, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+
+	// One more byte than allowed is read, so that larger bundles can be told apart from truncated ones.
+	content := &io.LimitedReader{R: gzipReader, N: maxBundleContentSize + 1}
+	tarReader := tar.NewReader(content)
+	entries := make(map[string][]byte)
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) && content.N > 0 {
+			break
+		}
+		if err != nil {
+			return nil, bundleReadError(content, err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, bundleReadError(content, err)
+		}
+		if content.N == 0 {
+			return nil, bundleReadError(content, nil)
+		}
+
+		entries[path.Clean(header.Name)] = data
+	}
+
+	if _, ok := entries[bundleManifestName]; !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidBundle, bundleManifestName)
+	}
+
+	return entries, nil
+}
+
+// bundleReadError wraps an error reading the bundle content, which may be caused by exceeding its maximum size.
+// Other errors are wrapped, so that their cause (e.g. a request body exceeding its size limit) can be checked.
+func bundleReadError(content *io.LimitedReader, err error) error {
+	if content.N == 0 {
+		return fmt.Errorf("%w: content exceeds %d bytes", ErrInvalidBundle, maxBundleContentSize)
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+}
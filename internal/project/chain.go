@@ -0,0 +1,854 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	emulatorstore "fri-flowser-playground/internal/emulator/store"
+	"github.com/onflow/flow-emulator/convert"
+	"github.com/onflow/flow-emulator/storage"
+	"github.com/onflow/flow-emulator/types"
+	flowgo "github.com/onflow/flow-go/model/flow"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidChainQuery   = errors.New("invalid chain query")
+	ErrCollectionNotFound  = errors.New("collection not found")
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+type TransactionStatus string
+
+const (
+	TransactionSucceeded TransactionStatus = "succeeded"
+	TransactionFailed    TransactionStatus = "failed"
+)
+
+// ChainQuery filters and paginates the blocks, collections, transactions, results and events of the project chain.
+// Results are ordered from the newest to the oldest.
+type ChainQuery struct {
+	// StartHeight and EndHeight limit results to blocks within the inclusive height range, unbounded if nil.
+	StartHeight *uint64
+	EndHeight   *uint64
+	// EventType only matches events of the fully qualified type, e.g. "A.f8d6e0586b0a20c7.HelloWorld.GreetingChanged".
+	EventType string
+	// Status only matches transactions, results and events of transactions with the given status.
+	Status TransactionStatus
+	// Address only matches transactions, results and events of transactions
+	// that the account proposed, paid for or authorized.
+	Address string
+	// TransactionID only matches events emitted by the transaction.
+	TransactionID string
+	Offset        int
+	// Limit is the maximum number of items returned, a default is used if zero.
+	Limit int
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Total is the number of items matching the query, regardless of the offset and limit.
+	// It's omitted for queries filtering by event type, transaction, status or address,
+	// since those items can only be counted by walking the whole chain.
+	Total *int `json:"total,omitempty"`
+	// HasMore is set if there are matching items after the page.
+	HasMore bool `json:"hasMore"`
+	Offset  int  `json:"offset"`
+	Limit   int  `json:"limit"`
+}
+
+type ChainBlock struct {
+	ID               string    `json:"id"`
+	ParentID         string    `json:"parentId"`
+	Height           uint64    `json:"height"`
+	Timestamp        time.Time `json:"timestamp"`
+	CollectionIDs    []string  `json:"collectionIds"`
+	TransactionCount int       `json:"transactionCount"`
+}
+
+type ChainCollection struct {
+	ID             string   `json:"id"`
+	BlockID        string   `json:"blockId"`
+	BlockHeight    uint64   `json:"blockHeight"`
+	TransactionIDs []string `json:"transactionIds"`
+}
+
+type ChainProposalKey struct {
+	Address        string `json:"address"`
+	KeyIndex       uint64 `json:"keyIndex"`
+	SequenceNumber uint64 `json:"sequenceNumber"`
+}
+
+type ChainTransaction struct {
+	ID          string            `json:"id"`
+	BlockID     string            `json:"blockId"`
+	BlockHeight uint64            `json:"blockHeight"`
+	Status      TransactionStatus `json:"status"`
+	Script      string            `json:"script"`
+	// Arguments are encoded as JSON-Cadence.
+	Arguments        []json.RawMessage `json:"arguments"`
+	ReferenceBlockID string            `json:"referenceBlockId"`
+	ComputeLimit     uint64            `json:"computeLimit"`
+	ProposalKey      ChainProposalKey  `json:"proposalKey"`
+	Payer            string            `json:"payer"`
+	Authorizers      []string          `json:"authorizers"`
+}
+
+type ChainTransactionResult struct {
+	TransactionID string            `json:"transactionId"`
+	BlockID       string            `json:"blockId"`
+	BlockHeight   uint64            `json:"blockHeight"`
+	Status        TransactionStatus `json:"status"`
+	Error         *CadenceError     `json:"error,omitempty"`
+	Logs          []string          `json:"logs"`
+	Events        []Event           `json:"events"`
+}
+
+type ChainEvent struct {
+	Event
+	BlockID     string `json:"blockId"`
+	BlockHeight uint64 `json:"blockHeight"`
+}
+
+// Blocks returns the blocks within the height range of the query.
+func (p *Project) Blocks(query ChainQuery) (*Page[ChainBlock], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	store := p.blockchain.Storage()
+
+	page, err := newChainPage[*flowgo.Block](p, query, func(counts emulatorstore.ChainCounts) int {
+		return counts.Blocks
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = page.walk(p, func(block *flowgo.Block) error {
+		return page.add(block)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(page, func(block *flowgo.Block) (ChainBlock, error) {
+		return newChainBlock(store, block)
+	})
+}
+
+// Block returns the block with the given height or hex encoded ID.
+func (p *Project) Block(heightOrID string) (*ChainBlock, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	store := p.blockchain.Storage()
+
+	var block *flowgo.Block
+	var err error
+
+	if height, parseErr := strconv.ParseUint(heightOrID, 10, 64); parseErr == nil {
+		block, err = store.BlockByHeight(context.Background(), height)
+	} else if id, parseErr := flowgo.HexStringToIdentifier(heightOrID); parseErr == nil {
+		block, err = store.BlockByID(context.Background(), id)
+	} else {
+		return nil, fmt.Errorf("%w: %s is neither a block height nor ID", ErrInvalidChainQuery, heightOrID)
+	}
+
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrBlockNotFound, heightOrID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chainBlock, err := newChainBlock(store, block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chainBlock, nil
+}
+
+// Collections returns the collections of the blocks within the height range of the query.
+func (p *Project) Collections(query ChainQuery) (*Page[ChainCollection], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	store := p.blockchain.Storage()
+
+	page, err := newChainPage[ChainCollection](p, query, func(counts emulatorstore.ChainCounts) int {
+		return counts.Collections
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = page.walk(p, func(block *flowgo.Block) error {
+		guarantees := block.Payload.Guarantees
+		for i := len(guarantees) - 1; i >= 0; i-- {
+			collection, err := store.CollectionByID(context.Background(), guarantees[i].CollectionID)
+			if err != nil {
+				return err
+			}
+
+			if err := page.add(newChainCollection(block, collection)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(page, func(collection ChainCollection) (ChainCollection, error) {
+		return collection, nil
+	})
+}
+
+// Collection returns the collection with the given hex encoded ID.
+func (p *Project) Collection(id string) (*ChainCollection, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	store := p.blockchain.Storage()
+
+	collectionID, err := flowgo.HexStringToIdentifier(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid collection ID %s", ErrInvalidChainQuery, id)
+	}
+
+	collection, err := store.CollectionByID(context.Background(), collectionID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Collections don't reference their block, but the results of their transactions do.
+	var block *flowgo.Block
+	if len(collection.Transactions) > 0 {
+		result, err := store.TransactionResultByID(context.Background(), collection.Transactions[0])
+		if err != nil {
+			return nil, err
+		}
+
+		block, err = store.BlockByID(context.Background(), result.BlockID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	chainCollection := newChainCollection(block, collection)
+	return &chainCollection, nil
+}
+
+// Transactions returns the transactions matching the height range, status and address of the query.
+func (p *Project) Transactions(query ChainQuery) (*Page[ChainTransaction], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	page, err := p.chainTransactions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(page, func(transaction chainTransaction) (ChainTransaction, error) {
+		return newChainTransaction(transaction), nil
+	})
+}
+
+// Transaction returns the transaction with the given hex encoded ID.
+func (p *Project) Transaction(id string) (*ChainTransaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	transaction, err := p.chainTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+
+	chainTransaction := newChainTransaction(*transaction)
+	return &chainTransaction, nil
+}
+
+// TransactionResults returns the results of the transactions matching the height range, status and address of the query.
+func (p *Project) TransactionResults(query ChainQuery) (*Page[ChainTransactionResult], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	page, err := p.chainTransactions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(page, newChainTransactionResult)
+}
+
+// TransactionResult returns the result of the transaction with the given hex encoded ID.
+func (p *Project) TransactionResult(id string) (*ChainTransactionResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	transaction, err := p.chainTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := newChainTransactionResult(*transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Events returns the events matching the query.
+func (p *Project) Events(query ChainQuery) (*Page[ChainEvent], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	store := p.blockchain.Storage()
+
+	type blockEvent struct {
+		block *flowgo.Block
+		event flowgo.Event
+	}
+
+	var count func(emulatorstore.ChainCounts) int
+	if query.EventType == "" && query.TransactionID == "" && query.Status == "" && query.Address == "" {
+		count = func(counts emulatorstore.ChainCounts) int {
+			return counts.Events
+		}
+	}
+
+	page, err := newChainPage[blockEvent](p, query, count)
+	if err != nil {
+		return nil, err
+	}
+
+	// The status and address filters match the transaction of the event, which is shared by many events.
+	matches := make(map[flowgo.Identifier]bool)
+
+	err = page.walk(p, func(block *flowgo.Block) error {
+		blockEvents, err := store.EventsByHeight(context.Background(), block.Header.Height, query.EventType)
+		if err != nil {
+			return err
+		}
+
+		for i := len(blockEvents) - 1; i >= 0; i-- {
+			event := blockEvents[i]
+
+			if query.TransactionID != "" && event.TransactionID.String() != query.TransactionID {
+				continue
+			}
+
+			if query.Status != "" || query.Address != "" {
+				match, ok := matches[event.TransactionID]
+				if !ok {
+					match = p.transactionMatches(event.TransactionID, query)
+					matches[event.TransactionID] = match
+				}
+				if !match {
+					continue
+				}
+			}
+
+			if err := page.add(blockEvent{block: block, event: event}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(page, func(blockEvent blockEvent) (ChainEvent, error) {
+		return newChainEvent(blockEvent.block, blockEvent.event)
+	})
+}
+
+// chainTransaction is a transaction stored on the project chain, together with its result.
+type chainTransaction struct {
+	body   flowgo.TransactionBody
+	result types.StorableTransactionResult
+}
+
+func (t chainTransaction) status() TransactionStatus {
+	if t.result.ErrorCode != 0 || t.result.ErrorMessage != "" {
+		return TransactionFailed
+	}
+	return TransactionSucceeded
+}
+
+// involves returns whether the account proposed, paid for or authorized the transaction.
+func (t chainTransaction) involves(address flowgo.Address) bool {
+	return t.body.ProposalKey.Address == address ||
+		t.body.Payer == address ||
+		slices.Contains(t.body.Authorizers, address)
+}
+
+func (t chainTransaction) matches(query ChainQuery) bool {
+	if query.Status != "" && t.status() != query.Status {
+		return false
+	}
+
+	if query.Address != "" && !t.involves(flowgo.HexToAddress(query.Address)) {
+		return false
+	}
+
+	return true
+}
+
+// chainTransactions returns the page of transactions matching the query, from the newest to the oldest.
+func (p *Project) chainTransactions(query ChainQuery) (*chainPage[chainTransaction], error) {
+	var count func(emulatorstore.ChainCounts) int
+	if query.Status == "" && query.Address == "" {
+		count = func(counts emulatorstore.ChainCounts) int {
+			return counts.Transactions
+		}
+	}
+
+	page, err := newChainPage[chainTransaction](p, query, count)
+	if err != nil {
+		return nil, err
+	}
+
+	store := p.blockchain.Storage()
+
+	err = page.walk(p, func(block *flowgo.Block) error {
+		guarantees := block.Payload.Guarantees
+		for i := len(guarantees) - 1; i >= 0; i-- {
+			collection, err := store.CollectionByID(context.Background(), guarantees[i].CollectionID)
+			if err != nil {
+				return err
+			}
+
+			for j := len(collection.Transactions) - 1; j >= 0; j-- {
+				transaction, err := loadChainTransaction(store, collection.Transactions[j])
+				if err != nil {
+					return err
+				}
+
+				if !transaction.matches(query) {
+					continue
+				}
+
+				if err := page.add(*transaction); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (p *Project) chainTransaction(id string) (*chainTransaction, error) {
+	transactionID, err := flowgo.HexStringToIdentifier(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid transaction ID %s", ErrInvalidChainQuery, id)
+	}
+
+	transaction, err := loadChainTransaction(p.blockchain.Storage(), transactionID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, id)
+	}
+
+	return transaction, err
+}
+
+// transactionMatches returns whether the stored transaction with the given ID matches the status and address of the query.
+// System transactions, e.g. those executed at genesis, aren't stored and never match.
+func (p *Project) transactionMatches(id flowgo.Identifier, query ChainQuery) bool {
+	transaction, err := loadChainTransaction(p.blockchain.Storage(), id)
+	if err != nil {
+		return false
+	}
+	return transaction.matches(query)
+}
+
+func loadChainTransaction(store storage.Store, id flowgo.Identifier) (*chainTransaction, error) {
+	body, err := store.TransactionByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := store.TransactionResultByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chainTransaction{body: body, result: result}, nil
+}
+
+// errPageFull stops walking the chain once the items of a page are collected.
+var errPageFull = errors.New("page is full")
+
+// chainPage collects the items of a page while walking the chain from the newest to the oldest block.
+type chainPage[S any] struct {
+	query ChainQuery
+	limit int
+	// skip is the number of matching items to skip before the page starts.
+	skip  int
+	items []S
+	more  bool
+	// total is the number of matching items, nil if they can't be counted without walking the chain.
+	total *int
+	// startHeight and endHeight are the inclusive range of the blocks to walk, which is empty if done is set.
+	startHeight uint64
+	endHeight   uint64
+	done        bool
+}
+
+// newChainPage locates the page of the query within the chain.
+// If the items of the page aren't filtered beyond the height range, count returns the number of items
+// committed up to a block height, so that the page is located without walking the blocks before it.
+// Otherwise count is nil and the blocks are walked until the page is collected.
+func newChainPage[S any](p *Project, query ChainQuery, count func(emulatorstore.ChainCounts) int) (*chainPage[S], error) {
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	limit = min(limit, maxPageLimit)
+
+	page := &chainPage[S]{
+		query: query,
+		limit: limit,
+		skip:  query.Offset,
+		items: make([]S, 0),
+	}
+
+	latestHeight, err := p.blockchain.Storage().LatestBlockHeight(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	page.endHeight = latestHeight
+	if query.EndHeight != nil && *query.EndHeight < page.endHeight {
+		page.endHeight = *query.EndHeight
+	}
+
+	if query.StartHeight != nil {
+		page.startHeight = *query.StartHeight
+	}
+
+	if page.startHeight > page.endHeight {
+		page.done = true
+		if count != nil {
+			page.total = new(int)
+		}
+		return page, nil
+	}
+
+	if count == nil {
+		return page, nil
+	}
+
+	countAt := func(height uint64) int {
+		return count(p.blockchain.ChainCounts(height))
+	}
+
+	before := 0
+	if page.startHeight > 0 {
+		before = countAt(page.startHeight - 1)
+	}
+
+	total := countAt(page.endHeight) - before
+	page.total = &total
+
+	if query.Offset >= total {
+		page.done = true
+		return page, nil
+	}
+
+	// Items are ordered from the newest to the oldest, so the first item of the page is
+	// the item at the following position counting from the oldest item within the height range.
+	position := total - 1 - query.Offset
+
+	// The counts increase with the height, so the block of the first item is found by a binary search.
+	blocks := int(page.endHeight - page.startHeight + 1)
+	index := sort.Search(blocks, func(i int) bool {
+		return countAt(page.startHeight+uint64(i))-before > position
+	})
+
+	page.endHeight = page.startHeight + uint64(index)
+	// Newer items of the block are skipped, as the block items are also walked from the newest.
+	page.skip = countAt(page.endHeight) - before - 1 - position
+
+	return page, nil
+}
+
+// add collects the item, errPageFull is returned once the page is complete.
+func (c *chainPage[S]) add(item S) error {
+	if c.skip > 0 {
+		c.skip--
+		return nil
+	}
+
+	if len(c.items) == c.limit {
+		c.more = true
+		return errPageFull
+	}
+
+	c.items = append(c.items, item)
+	return nil
+}
+
+// walk calls the function with each block of the page, from the newest to the oldest,
+// until the function returns errPageFull.
+func (c *chainPage[S]) walk(p *Project, fn func(block *flowgo.Block) error) error {
+	if c.done {
+		return nil
+	}
+
+	store := p.blockchain.Storage()
+
+	for height := c.endHeight + 1; height > c.startHeight; height-- {
+		block, err := store.BlockByHeight(context.Background(), height-1)
+		if err != nil {
+			return err
+		}
+
+		err = fn(block)
+		if errors.Is(err, errPageFull) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q ChainQuery) validate() error {
+	if q.Offset < 0 || q.Limit < 0 {
+		return fmt.Errorf("%w: offset and limit must not be negative", ErrInvalidChainQuery)
+	}
+
+	if q.StartHeight != nil && q.EndHeight != nil && *q.StartHeight > *q.EndHeight {
+		return fmt.Errorf("%w: start height is greater than end height", ErrInvalidChainQuery)
+	}
+
+	if q.Status != "" && q.Status != TransactionSucceeded && q.Status != TransactionFailed {
+		return fmt.Errorf("%w: status must be %s or %s", ErrInvalidChainQuery, TransactionSucceeded, TransactionFailed)
+	}
+
+	if _, ok := parseAddress(q.Address); q.Address != "" && !ok {
+		return fmt.Errorf("%w: invalid address %s", ErrInvalidChainQuery, q.Address)
+	}
+
+	return nil
+}
+
+// newPage converts the collected items of the page.
+func newPage[S any, T any](page *chainPage[S], convert func(S) (T, error)) (*Page[T], error) {
+	result := &Page[T]{
+		Items:   make([]T, 0, len(page.items)),
+		Total:   page.total,
+		HasMore: page.more,
+		Offset:  page.query.Offset,
+		Limit:   page.limit,
+	}
+
+	if page.total != nil {
+		result.HasMore = page.query.Offset+len(page.items) < *page.total
+	}
+
+	for _, item := range page.items {
+		converted, err := convert(item)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, converted)
+	}
+
+	return result, nil
+}
+
+func newChainBlock(store storage.Store, block *flowgo.Block) (ChainBlock, error) {
+	chainBlock := ChainBlock{
+		ID:            block.ID().String(),
+		ParentID:      block.Header.ParentID.String(),
+		Height:        block.Header.Height,
+		Timestamp:     block.Header.Timestamp,
+		CollectionIDs: make([]string, 0, len(block.Payload.Guarantees)),
+	}
+
+	for _, guarantee := range block.Payload.Guarantees {
+		collection, err := store.CollectionByID(context.Background(), guarantee.CollectionID)
+		if err != nil {
+			return ChainBlock{}, err
+		}
+
+		chainBlock.CollectionIDs = append(chainBlock.CollectionIDs, guarantee.CollectionID.String())
+		chainBlock.TransactionCount += len(collection.Transactions)
+	}
+
+	return chainBlock, nil
+}
+
+// newChainCollection converts the collection, the block is unknown if nil.
+func newChainCollection(block *flowgo.Block, collection flowgo.LightCollection) ChainCollection {
+	chainCollection := ChainCollection{
+		ID:             collection.ID().String(),
+		TransactionIDs: make([]string, 0, len(collection.Transactions)),
+	}
+
+	if block != nil {
+		chainCollection.BlockID = block.ID().String()
+		chainCollection.BlockHeight = block.Header.Height
+	}
+
+	for _, id := range collection.Transactions {
+		chainCollection.TransactionIDs = append(chainCollection.TransactionIDs, id.String())
+	}
+
+	return chainCollection
+}
+
+func newChainEvent(block *flowgo.Block, flowEvent flowgo.Event) (ChainEvent, error) {
+	sdkEvent, err := convert.FlowEventToSDK(flowEvent)
+	if err != nil {
+		return ChainEvent{}, err
+	}
+
+	event, err := newEvent(sdkEvent)
+	if err != nil {
+		return ChainEvent{}, err
+	}
+
+	return ChainEvent{
+		Event:       event,
+		BlockID:     block.ID().String(),
+		BlockHeight: block.Header.Height,
+	}, nil
+}
+
+func newChainTransaction(transaction chainTransaction) ChainTransaction {
+	body := transaction.body
+
+	chainTransaction := ChainTransaction{
+		ID:               body.ID().String(),
+		BlockID:          transaction.result.BlockID.String(),
+		BlockHeight:      transaction.result.BlockHeight,
+		Status:           transaction.status(),
+		Script:           string(body.Script),
+		Arguments:        make([]json.RawMessage, 0, len(body.Arguments)),
+		ReferenceBlockID: body.ReferenceBlockID.String(),
+		ComputeLimit:     body.GasLimit,
+		ProposalKey: ChainProposalKey{
+			Address:        body.ProposalKey.Address.HexWithPrefix(),
+			KeyIndex:       body.ProposalKey.KeyIndex,
+			SequenceNumber: body.ProposalKey.SequenceNumber,
+		},
+		Payer:       body.Payer.HexWithPrefix(),
+		Authorizers: make([]string, 0, len(body.Authorizers)),
+	}
+
+	for _, argument := range body.Arguments {
+		chainTransaction.Arguments = append(chainTransaction.Arguments, bytes.TrimSpace(argument))
+	}
+
+	for _, authorizer := range body.Authorizers {
+		chainTransaction.Authorizers = append(chainTransaction.Authorizers, authorizer.HexWithPrefix())
+	}
+
+	return chainTransaction
+}
+
+func newChainTransactionResult(transaction chainTransaction) (ChainTransactionResult, error) {
+	result := transaction.result
+
+	chainResult := ChainTransactionResult{
+		TransactionID: transaction.body.ID().String(),
+		BlockID:       result.BlockID.String(),
+		BlockHeight:   result.BlockHeight,
+		Status:        transaction.status(),
+		Logs:          result.Logs,
+		Events:        make([]Event, 0, len(result.Events)),
+	}
+
+	if chainResult.Logs == nil {
+		chainResult.Logs = make([]string, 0)
+	}
+
+	if chainResult.Status == TransactionFailed {
+		chainResult.Error = newCadenceError(errors.New(result.ErrorMessage), nil)
+		chainResult.Error.Code = result.ErrorCode
+	}
+
+	for _, flowEvent := range result.Events {
+		sdkEvent, err := convert.FlowEventToSDK(flowEvent)
+		if err != nil {
+			return ChainTransactionResult{}, err
+		}
+
+		event, err := newEvent(sdkEvent)
+		if err != nil {
+			return ChainTransactionResult{}, err
+		}
+
+		chainResult.Events = append(chainResult.Events, event)
+	}
+
+	return chainResult, nil
+}
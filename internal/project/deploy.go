@@ -0,0 +1,423 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit"
+	flowproject "github.com/onflow/flowkit/project"
+	"path"
+	"strings"
+	"time"
+)
+
+type ContractDeploymentStatus string
+
+const (
+	ContractAdded     ContractDeploymentStatus = "added"
+	ContractUpdated   ContractDeploymentStatus = "updated"
+	ContractUnchanged ContractDeploymentStatus = "unchanged"
+	ContractFailed    ContractDeploymentStatus = "failed"
+)
+
+type ContractDeployment struct {
+	Name          string                   `json:"name"`
+	AccountName   string                   `json:"accountName"`
+	Address       string                   `json:"address"`
+	Location      string                   `json:"location"`
+	Status        ContractDeploymentStatus `json:"status"`
+	TransactionID string                   `json:"transactionId,omitempty"`
+	Error         *CadenceError            `json:"error,omitempty"`
+}
+
+type Deployment struct {
+	Contracts  []ContractDeployment `json:"contracts"`
+	DeployedAt time.Time            `json:"deployedAt"`
+}
+
+// Deploy deploys the contracts from the given changed files and all contracts that depend on them.
+// All contracts in the project deployments are deployed if no paths are given.
+func (p *Project) Deploy(changedPaths ...string) (*Deployment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.deploy(changedPaths...)
+}
+
+// LastDeployment returns the result of the most recent deployment, or nil if nothing was deployed yet.
+func (p *Project) LastDeployment() (*Deployment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.lastDeployment, nil
+}
+
+func (p *Project) SetAutoDeploy(autoDeploy bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	p.autoDeploy = autoDeploy
+	p.saveState()
+
+	return nil
+}
+
+// autoRedeploy redeploys contracts affected by changes to the given files if auto deploy is enabled.
+// Changes to the project configuration reload the flowkit state and redeploy all contracts.
+// Deployment failures are logged and recorded as the last deployment,
+// so that they don't fail the file change that triggered them.
+func (p *Project) autoRedeploy(changedPaths ...string) {
+	if !p.autoDeploy {
+		return
+	}
+
+	deployPaths := make([]string, 0, len(changedPaths))
+	for _, changedPath := range changedPaths {
+		if changedPath == path.Join(p.directory, configFileName) {
+			err := p.load()
+			if err != nil {
+				p.logger.Error().Err(err).Msg("Failed to reload project configuration")
+			}
+			return
+		}
+
+		if strings.HasSuffix(changedPath, ".cdc") {
+			deployPaths = append(deployPaths, changedPath)
+		}
+	}
+
+	if len(deployPaths) == 0 {
+		return
+	}
+
+	_, err := p.deploy(deployPaths...)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("Failed to redeploy contracts")
+	}
+}
+
+func (p *Project) deploy(changedPaths ...string) (*Deployment, error) {
+	state, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	network := p.kit.Network()
+
+	contracts, err := state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := state.AliasesForNetwork(network)
+
+	changed := make(map[string]bool)
+	for _, changedPath := range changedPaths {
+		changed[cleanFilePath(changedPath)] = true
+	}
+
+	result := &Deployment{
+		Contracts:  make([]ContractDeployment, 0),
+		DeployedAt: time.Now(),
+	}
+
+	// Contracts with syntax errors can't be sorted by their imports, so they are reported as failed upfront.
+	failed := make(map[string]bool)
+	reported := make(map[string]bool)
+	parsed := make([]*flowproject.Contract, 0, len(contracts))
+	for _, contract := range contracts {
+		_, err := parser.ParseProgram(nil, contract.Code(), parser.Config{})
+
+		if err == nil {
+			parsed = append(parsed, contract)
+			continue
+		}
+
+		failed[contract.Name] = true
+		if len(changed) == 0 || changed[p.contractPath(contract)] {
+			result.Contracts = append(result.Contracts, p.failedContractDeployment(contract, err))
+			reported[contract.Name] = true
+		}
+	}
+
+	// Contracts importing failed contracts (directly or through other contracts) can't be deployed either,
+	// they are reported if the failed contract was.
+	for excluded := true; excluded; {
+		excluded = false
+		remaining := make([]*flowproject.Contract, 0, len(parsed))
+
+		for _, contract := range parsed {
+			dependency, position, ok := p.failedDependency(contract, contracts, failed)
+			if !ok {
+				remaining = append(remaining, contract)
+				continue
+			}
+
+			failed[contract.Name] = true
+			excluded = true
+
+			if len(changed) == 0 || changed[p.contractPath(contract)] || reported[dependency] {
+				result.Contracts = append(result.Contracts, p.failedDependencyDeployment(contract, dependency, position))
+				reported[contract.Name] = true
+			}
+		}
+
+		parsed = remaining
+	}
+
+	deployment, err := flowproject.NewDeployment(parsed, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	// Contracts are sorted so that dependencies are deployed before the contracts that import them.
+	sorted, err := deployment.Sort()
+	if err != nil {
+		return nil, err
+	}
+
+	affected := make(map[string]bool)
+
+	for _, contract := range sorted {
+		if len(changed) > 0 {
+			isAffected := changed[p.contractPath(contract)]
+			for _, dependency := range p.contractDependencies(contract, sorted) {
+				isAffected = isAffected || affected[dependency]
+			}
+
+			if !isAffected {
+				continue
+			}
+
+			affected[contract.Name] = true
+		}
+
+		result.Contracts = append(result.Contracts, p.deployContract(contract, contracts, aliases))
+	}
+
+	p.lastDeployment = result
+	p.saveState()
+
+	p.logger.Info().Msg(fmt.Sprintf("Deployed %d contracts", len(result.Contracts)))
+
+	return result, nil
+}
+
+func (p *Project) deployContract(
+	contract *flowproject.Contract,
+	contracts []*flowproject.Contract,
+	aliases flowproject.LocationAliases,
+) ContractDeployment {
+	deployment := newContractDeployment(p.contractPath(contract), contract)
+
+	fail := func(err error) ContractDeployment {
+		return p.failedContractDeployment(contract, err)
+	}
+
+	program, err := flowproject.NewProgram(contract.Code(), contract.Args, contract.Location())
+	if err != nil {
+		return fail(err)
+	}
+
+	if program.HasImports() {
+		program, err = flowproject.NewImportReplacer(contracts, aliases).Replace(program)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	state, err := p.kit.State()
+	if err != nil {
+		return fail(err)
+	}
+
+	account, err := state.Accounts().ByName(contract.AccountName)
+	if err != nil {
+		return fail(err)
+	}
+
+	flowAccount, err := p.kit.Gateway().GetAccount(context.Background(), account.Address)
+	if err != nil {
+		return fail(err)
+	}
+
+	existingCode, exists := flowAccount.Contracts[contract.Name]
+
+	if exists && bytes.Equal(existingCode, program.Code()) {
+		deployment.Status = ContractUnchanged
+		return deployment
+	}
+
+	txID, _, err := p.kit.AddContract(
+		context.Background(),
+		account,
+		flowkit.Script{Code: contract.Code(), Args: contract.Args, Location: contract.Location()},
+		flowkit.UpdateExistingContract(true),
+	)
+
+	if err != nil {
+		deployment = fail(err)
+	} else if exists {
+		deployment.Status = ContractUpdated
+	} else {
+		deployment.Status = ContractAdded
+	}
+
+	if txID != flow.EmptyID {
+		deployment.TransactionID = txID.String()
+	}
+
+	return deployment
+}
+
+func newContractDeployment(location string, contract *flowproject.Contract) ContractDeployment {
+	return ContractDeployment{
+		Name:        contract.Name,
+		AccountName: contract.AccountName,
+		Address:     contract.AccountAddress.Hex(),
+		Location:    location,
+	}
+}
+
+func (p *Project) failedContractDeployment(contract *flowproject.Contract, err error) ContractDeployment {
+	deployment := newContractDeployment(p.contractPath(contract), contract)
+	deployment.Status = ContractFailed
+
+	// Errors reported by the emulator refer to the contract by its address location.
+	deployment.Error = newCadenceError(err, map[string]string{
+		contractAddressLocation(contract): deployment.Location,
+	})
+	if deployment.Error.Location == "" {
+		deployment.Error.Location = deployment.Location
+	}
+
+	p.logger.Error().Msg(fmt.Sprintf("Failed to deploy contract %s: %s", contract.Name, err))
+
+	return deployment
+}
+
+// failedDependencyDeployment reports a contract that isn't deployed since it imports a failed contract,
+// the error is positioned at the import declaration.
+func (p *Project) failedDependencyDeployment(
+	contract *flowproject.Contract,
+	dependency string,
+	position ast.Position,
+) ContractDeployment {
+	deployment := p.failedContractDeployment(contract, fmt.Errorf("imported contract %s failed to deploy", dependency))
+	deployment.Error.Line = position.Line
+	deployment.Error.Column = position.Column
+
+	return deployment
+}
+
+// failedDependency returns the first contract imported by the contract that failed, with the position of its import.
+func (p *Project) failedDependency(
+	contract *flowproject.Contract,
+	contracts []*flowproject.Contract,
+	failed map[string]bool,
+) (string, ast.Position, bool) {
+	for _, contractImport := range p.contractImports(contract, contracts) {
+		if failed[contractImport.name] {
+			return contractImport.name, contractImport.position, true
+		}
+	}
+
+	return "", ast.Position{}, false
+}
+
+// contractDependencies returns names of the project contracts imported by the contract.
+func (p *Project) contractDependencies(contract *flowproject.Contract, contracts []*flowproject.Contract) []string {
+	dependencies := make([]string, 0)
+
+	for _, contractImport := range p.contractImports(contract, contracts) {
+		dependencies = append(dependencies, contractImport.name)
+	}
+
+	return dependencies
+}
+
+type contractImport struct {
+	name     string
+	position ast.Position
+}
+
+// contractImports returns the project contracts imported by the contract, with the positions of their imports.
+func (p *Project) contractImports(contract *flowproject.Contract, contracts []*flowproject.Contract) []contractImport {
+	program, err := parser.ParseProgram(nil, contract.Code(), parser.Config{})
+	if err != nil {
+		return nil
+	}
+
+	imports := make([]contractImport, 0)
+
+	for _, importDeclaration := range program.ImportDeclarations() {
+		location, ok := importDeclaration.Location.(common.StringLocation)
+		if !ok {
+			continue
+		}
+
+		for _, dependency := range contracts {
+			// Imports are either contract names (import "Foo") or paths relative to the importing contract.
+			importedPath := path.Join(path.Dir(contract.Location()), location.String())
+			if dependency.Name == location.String() || path.Clean(dependency.Location()) == importedPath {
+				imports = append(imports, contractImport{
+					name:     dependency.Name,
+					position: importDeclaration.StartPosition(),
+				})
+			}
+		}
+	}
+
+	return imports
+}
+
+// contractPath returns the repository path of the contract source file.
+func (p *Project) contractPath(contract *flowproject.Contract) string {
+	return path.Join(p.directory, contract.Location())
+}
+
+// programLocations maps Cadence locations of the deployed contracts and the given program to project file paths,
+// so that errors can be reported at the source files.
+func (p *Project) programLocations(programLocation string, filePath string) map[string]string {
+	locations := make(map[string]string)
+
+	if filePath != "" {
+		locations[programLocation] = cleanFilePath(filePath)
+	}
+
+	state, err := p.kit.State()
+	if err != nil {
+		return locations
+	}
+
+	contracts, err := state.DeploymentContractsByNetwork(p.kit.Network())
+	if err != nil {
+		return locations
+	}
+
+	for _, contract := range contracts {
+		locations[contractAddressLocation(contract)] = p.contractPath(contract)
+	}
+
+	return locations
+}
+
+func contractAddressLocation(contract *flowproject.Contract) string {
+	return fmt.Sprintf("%s.%s", contract.AccountAddress.Hex(), contract.Name)
+}
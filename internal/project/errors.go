@@ -0,0 +1,80 @@
+package project
+
+import (
+	"errors"
+	"github.com/onflow/cadence/runtime/ast"
+	"regexp"
+	"strconv"
+)
+
+// CadenceError is a structured representation of an error reported by the Cadence parser, checker or runtime.
+type CadenceError struct {
+	// Code is the FVM error code, zero if the error didn't originate from the FVM.
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message"`
+	// Location of the program where the error occurred,
+	// which is a project file path if the program is known to the project.
+	Location string `json:"location,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+func (e *CadenceError) Error() string {
+	return e.Message
+}
+
+var errorCodePattern = regexp.MustCompile(`\[Error Code: (\d+)]`)
+
+// Matches the position line of pretty printed Cadence errors, e.g. "--> f8d6e0586b0a20c7.HelloWorld:5:8".
+var errorPositionPattern = regexp.MustCompile(`-->\s+(\S+):(\d+):(\d+)`)
+
+// newCadenceError converts the error to a structured error.
+// Cadence locations (e.g. "f8d6e0586b0a20c7.HelloWorld") that are known project files are given in locations,
+// and positions within those are preferred over positions in other programs.
+func newCadenceError(err error, locations map[string]string) *CadenceError {
+	cadenceError := &CadenceError{
+		Message: err.Error(),
+	}
+
+	if match := errorCodePattern.FindStringSubmatch(cadenceError.Message); match != nil {
+		cadenceError.Code, _ = strconv.Atoi(match[1])
+	}
+
+	// Errors returned from the emulator are pretty printed with the location of each position,
+	// while the errors returned by the parser and checker only carry their position.
+	matches := errorPositionPattern.FindAllStringSubmatch(cadenceError.Message, -1)
+	if len(matches) == 0 {
+		if position, ok := errorPosition(err); ok {
+			cadenceError.Line = position.Line
+			cadenceError.Column = position.Column
+		}
+		return cadenceError
+	}
+
+	match := matches[0]
+	for _, m := range matches {
+		if _, ok := locations[m[1]]; ok {
+			match = m
+			break
+		}
+	}
+
+	cadenceError.Location = match[1]
+	if filePath, ok := locations[match[1]]; ok {
+		cadenceError.Location = filePath
+	}
+	cadenceError.Line, _ = strconv.Atoi(match[2])
+	cadenceError.Column, _ = strconv.Atoi(match[3])
+
+	return cadenceError
+}
+
+// errorPosition finds the position of the first error (or nested error) that has one.
+func errorPosition(err error) (ast.Position, bool) {
+	var positioned ast.HasPosition
+	if errors.As(err, &positioned) {
+		return positioned.StartPosition(), true
+	}
+
+	return ast.Position{}, false
+}
@@ -0,0 +1,78 @@
+package project
+
+import (
+	"encoding/json"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// Event is a decoded Cadence event emitted by a transaction.
+type Event struct {
+	// Type is the fully qualified event type, e.g. "A.f8d6e0586b0a20c7.HelloWorld.GreetingChanged".
+	Type             string       `json:"type"`
+	TransactionID    string       `json:"transactionId"`
+	TransactionIndex int          `json:"transactionIndex"`
+	EventIndex       int          `json:"eventIndex"`
+	Fields           []EventField `json:"fields"`
+}
+
+type EventField struct {
+	Name string `json:"name"`
+	// Value is encoded as JSON-Cadence, which includes the Cadence type of the value.
+	Value json.RawMessage `json:"value"`
+}
+
+func newEvent(event flow.Event) (Event, error) {
+	values := event.Value.GetFieldValues()
+	fields := make([]EventField, 0, len(values))
+
+	for i, field := range event.Value.GetFields() {
+		if i >= len(values) {
+			break
+		}
+
+		value, err := jsoncdc.Encode(values[i])
+		if err != nil {
+			return Event{}, err
+		}
+
+		fields = append(fields, EventField{
+			Name:  field.Identifier,
+			Value: value,
+		})
+	}
+
+	return Event{
+		Type:             event.Type,
+		TransactionID:    event.TransactionID.String(),
+		TransactionIndex: event.TransactionIndex,
+		EventIndex:       event.EventIndex,
+		Fields:           fields,
+	}, nil
+}
+
+func newEvents(events []flow.Event) ([]Event, error) {
+	decoded := make([]Event, 0, len(events))
+
+	for _, event := range events {
+		e, err := newEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, e)
+	}
+
+	return decoded, nil
+}
+
+// eventField returns the value of the event field with the given name, or nil if the event has no such field.
+func eventField(event cadence.Event, name string) cadence.Value {
+	values := event.GetFieldValues()
+	for i, field := range event.GetFields() {
+		if field.Identifier == name && i < len(values) {
+			return values[i]
+		}
+	}
+	return nil
+}
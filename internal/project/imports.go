@@ -0,0 +1,160 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/fvm/systemcontracts"
+	flowgo "github.com/onflow/flow-go/model/flow"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrUnresolvedImports = errors.New("unresolved imports")
+
+// importTarget is a contract that a string import can be resolved to.
+type importTarget struct {
+	name    string
+	address flow.Address
+}
+
+// resolveImports replaces the string imports of a script or transaction with address imports,
+// so that it can be executed on the emulator. Imports are resolved in this order:
+//   - file imports (e.g. import "./Foo.cdc" or import Foo from "../contracts/Foo.cdc"),
+//     relative to the location of the program in the repository,
+//   - contract names (e.g. import "Foo") of the contracts deployed by the project or aliased in flow.json,
+//   - names of the core contracts deployed on the emulator (e.g. import "FungibleToken").
+//
+// Programs that fail to parse are returned unchanged, so that the emulator reports the syntax error.
+func (p *Project) resolveImports(code []byte, location string) ([]byte, error) {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return code, nil
+	}
+
+	stringImports := make([]*ast.ImportDeclaration, 0)
+	for _, importDeclaration := range program.ImportDeclarations() {
+		if _, ok := importDeclaration.Location.(common.StringLocation); ok {
+			stringImports = append(stringImports, importDeclaration)
+		}
+	}
+
+	if len(stringImports) == 0 {
+		return code, nil
+	}
+
+	targets, err := p.importTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	// File imports are relative to the program, while contract locations are relative to the project directory.
+	programDirectory := "."
+	if location != "" {
+		programPath, err := filepath.Rel(p.directory, cleanFilePath(location))
+		if err != nil {
+			return nil, err
+		}
+		programDirectory = path.Dir(programPath)
+	}
+
+	unresolved := make([]string, 0)
+	resolved := make(map[*ast.ImportDeclaration]importTarget)
+
+	for _, importDeclaration := range stringImports {
+		imported := importDeclaration.Location.String()
+
+		target, ok := targets[path.Clean(path.Join(programDirectory, imported))]
+		if !ok {
+			target, ok = targets[imported]
+		}
+
+		if !ok {
+			unresolved = append(unresolved, fmt.Sprintf("%q", imported))
+			continue
+		}
+
+		resolved[importDeclaration] = target
+	}
+
+	if len(unresolved) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnresolvedImports, strings.Join(unresolved, ", "))
+	}
+
+	// Imports are replaced from the end, so that the offsets of the preceding imports stay valid.
+	sort.Slice(stringImports, func(i, j int) bool {
+		return stringImports[i].StartPos.Offset > stringImports[j].StartPos.Offset
+	})
+
+	resolvedCode := string(code)
+	for _, importDeclaration := range stringImports {
+		resolvedCode = resolvedCode[:importDeclaration.StartPos.Offset] +
+			addressImport(importDeclaration, resolved[importDeclaration]) +
+			resolvedCode[importDeclaration.EndPos.Offset+1:]
+	}
+
+	return []byte(resolvedCode), nil
+}
+
+// importTargets maps contract names and locations (relative to the project directory) to the contracts.
+func (p *Project) importTargets() (map[string]importTarget, error) {
+	targets := make(map[string]importTarget)
+
+	for _, contract := range systemcontracts.SystemContractsForChain(flowgo.Emulator).All() {
+		targets[contract.Name] = importTarget{
+			name:    contract.Name,
+			address: flow.Address(contract.Address),
+		}
+	}
+
+	state, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	network := p.kit.Network()
+
+	for _, contract := range *state.Contracts() {
+		alias := contract.Aliases.ByNetwork(network.Name)
+		if alias == nil {
+			continue
+		}
+
+		target := importTarget{name: contract.Name, address: alias.Address}
+		targets[path.Clean(contract.Location)] = target
+		targets[contract.Name] = target
+	}
+
+	contracts, err := state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, contract := range contracts {
+		target := importTarget{name: contract.Name, address: contract.AccountAddress}
+		targets[path.Clean(contract.Location())] = target
+		targets[contract.Name] = target
+	}
+
+	return targets, nil
+}
+
+// addressImport formats the import declaration as an import of the target contract.
+// File imports without identifiers (e.g. import "./Foo.cdc") import the contract by its name.
+func addressImport(importDeclaration *ast.ImportDeclaration, target importTarget) string {
+	identifiers := make([]string, 0, len(importDeclaration.Identifiers))
+	for _, identifier := range importDeclaration.Identifiers {
+		identifiers = append(identifiers, identifier.Identifier)
+	}
+
+	if len(identifiers) == 0 {
+		identifiers = append(identifiers, target.name)
+	}
+
+	return fmt.Sprintf("import %s from 0x%s", strings.Join(identifiers, ", "), target.address.Hex())
+}
@@ -0,0 +1,267 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidLogQuery = errors.New("invalid log query")
+
+// maxLogEntries is the number of log entries kept for each project, older entries are discarded first.
+const maxLogEntries = 5000
+
+// LogSource is the component that wrote a log entry.
+type LogSource string
+
+const (
+	LogSourceProject  LogSource = "project"
+	LogSourceEmulator LogSource = "emulator"
+	LogSourceFlowKit  LogSource = "flowkit"
+	LogSourceGit      LogSource = "git"
+)
+
+// logSourceFieldName is the zerolog field holding the source of an entry.
+const logSourceFieldName = "source"
+
+// logProjectFieldName is the zerolog field holding the project ID, which is omitted from entries.
+const logProjectFieldName = "project"
+
+type LogEntry struct {
+	// ID increases with each written entry and isn't reused when entries are discarded.
+	ID      uint64         `json:"id"`
+	Level   string         `json:"level"`
+	Time    time.Time      `json:"time"`
+	Source  LogSource      `json:"source"`
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// LogQuery filters log entries, zero values match all entries.
+type LogQuery struct {
+	// Level is the minimum level of the entries.
+	Level  string
+	Source LogSource
+	Since  *time.Time
+	Until  *time.Time
+	// Search matches entries containing the text in their message or fields, ignoring case.
+	Search string
+	// After only matches entries with a greater ID.
+	After *uint64
+	// Limit is the maximum number of entries, keeping the most recent ones.
+	Limit int
+}
+
+func (q LogQuery) validate() error {
+	if q.Limit < 0 {
+		return fmt.Errorf("%w: limit must not be negative", ErrInvalidLogQuery)
+	}
+
+	if _, err := zerolog.ParseLevel(q.Level); err != nil {
+		return fmt.Errorf("%w: unknown level %s", ErrInvalidLogQuery, q.Level)
+	}
+
+	switch q.Source {
+	case "", LogSourceProject, LogSourceEmulator, LogSourceFlowKit, LogSourceGit:
+	default:
+		return fmt.Errorf("%w: unknown source %s", ErrInvalidLogQuery, q.Source)
+	}
+
+	if q.Since != nil && q.Until != nil && q.Since.After(*q.Until) {
+		return fmt.Errorf("%w: since is after until", ErrInvalidLogQuery)
+	}
+
+	return nil
+}
+
+func (q LogQuery) matches(entry LogEntry) bool {
+	if q.Level != "" {
+		minLevel, _ := zerolog.ParseLevel(q.Level)
+		level, err := zerolog.ParseLevel(entry.Level)
+		if err != nil || level < minLevel {
+			return false
+		}
+	}
+
+	if q.Source != "" && q.Source != entry.Source {
+		return false
+	}
+
+	if q.Since != nil && entry.Time.Before(*q.Since) {
+		return false
+	}
+
+	if q.Until != nil && entry.Time.After(*q.Until) {
+		return false
+	}
+
+	if q.After != nil && entry.ID <= *q.After {
+		return false
+	}
+
+	return q.Search == "" || entry.contains(strings.ToLower(q.Search))
+}
+
+func (e LogEntry) contains(text string) bool {
+	if strings.Contains(strings.ToLower(e.Message), text) {
+		return true
+	}
+
+	for name, value := range e.Fields {
+		if strings.Contains(strings.ToLower(name), text) ||
+			strings.Contains(strings.ToLower(fmt.Sprint(value)), text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LogBuffer keeps the most recent log entries written by a zerolog logger.
+type LogBuffer struct {
+	mu sync.Mutex
+	// entries is a ring buffer, start is the index of the oldest of the count entries.
+	entries []LogEntry
+	start   int
+	count   int
+	nextID  uint64
+	// written is closed and replaced whenever an entry is written.
+	written chan struct{}
+}
+
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{
+		entries: make([]LogEntry, size),
+		written: make(chan struct{}),
+	}
+}
+
+var _ io.Writer = &LogBuffer{}
+
+// Write parses a JSON log line written by zerolog into an entry.
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	entry := parseLogEntry(p)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry.ID = b.nextID
+	b.nextID++
+
+	if b.count < len(b.entries) {
+		b.entries[(b.start+b.count)%len(b.entries)] = entry
+		b.count++
+	} else {
+		b.entries[b.start] = entry
+		b.start = (b.start + 1) % len(b.entries)
+	}
+
+	close(b.written)
+	b.written = make(chan struct{})
+
+	return len(p), nil
+}
+
+// Entries returns the entries matching the query, from oldest to newest.
+func (b *LogBuffer) Entries(query LogQuery) ([]LogEntry, error) {
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := make([]LogEntry, 0)
+	for i := 0; i < b.count; i++ {
+		entry := b.entries[(b.start+i)%len(b.entries)]
+		if query.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	if query.Limit > 0 && len(entries) > query.Limit {
+		entries = entries[len(entries)-query.Limit:]
+	}
+
+	return entries, nil
+}
+
+// Since returns the entries matching the query starting at the given ID, the ID following the returned entries
+// and a channel that is closed when the next entry is written.
+// Entries that were already discarded are skipped, so the returned entries start at the oldest kept one.
+// IDs after the next entry were given out by a previous buffer of the project (e.g. before a restart or restore),
+// in which case all kept entries are returned.
+func (b *LogBuffer) Since(id uint64, query LogQuery) ([]LogEntry, uint64, <-chan struct{}, error) {
+	if err := query.validate(); err != nil {
+		return nil, id, nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if id > b.nextID {
+		id = 0
+	}
+
+	entries := make([]LogEntry, 0)
+	for i := 0; i < b.count; i++ {
+		entry := b.entries[(b.start+i)%len(b.entries)]
+		if entry.ID >= id && query.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, b.nextID, b.written, nil
+}
+
+func parseLogEntry(p []byte) LogEntry {
+	var fields map[string]any
+
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&fields); err != nil {
+		return LogEntry{
+			Time:    time.Now(),
+			Source:  LogSourceProject,
+			Message: strings.TrimSpace(string(p)),
+		}
+	}
+
+	entry := LogEntry{
+		Level:   takeStringField(fields, zerolog.LevelFieldName),
+		Message: takeStringField(fields, zerolog.MessageFieldName),
+		Source:  LogSource(takeStringField(fields, logSourceFieldName)),
+	}
+
+	if entry.Source == "" {
+		entry.Source = LogSourceProject
+	}
+
+	entry.Time, _ = time.Parse(zerolog.TimeFieldFormat, takeStringField(fields, zerolog.TimestampFieldName))
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	delete(fields, logProjectFieldName)
+	if len(fields) > 0 {
+		entry.Fields = fields
+	}
+
+	return entry
+}
+
+// takeStringField removes the field and returns its value, if it's a string.
+func takeStringField(fields map[string]any, name string) string {
+	value, _ := fields[name].(string)
+	delete(fields, name)
+
+	return value
+}
@@ -0,0 +1,302 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flowkit/accounts"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const stateFileName = "project.json"
+
+// persistedState is the project state that is saved to the project data directory,
+// next to the repository and the emulator journal, so that the project can be restored after a restart.
+type persistedState struct {
+	ID              string    `json:"id"`
+	ProjectUrl      string    `json:"projectUrl"`
+	Ref             string    `json:"ref"`
+	Directory       string    `json:"directory"`
+	Depth           int       `json:"depth"`
+	AutoDeploy      bool      `json:"autoDeploy"`
+	TransactionFees bool      `json:"transactionFees"`
+	StorageLimit    bool      `json:"storageLimit"`
+	AccessAPI       bool      `json:"accessApi"`
+	CreatedAt       time.Time `json:"createdAt"`
+	// Accounts are the flow.json accounts with the addresses and keys they were created with on the emulator.
+	Accounts       []persistedAccount `json:"accounts"`
+	LastDeployment *Deployment        `json:"lastDeployment,omitempty"`
+	// Snapshots hold the project state of the snapshots, whose chain state is persisted by the emulator store.
+	Snapshots []persistedSnapshot `json:"snapshots,omitempty"`
+}
+
+type persistedSnapshot struct {
+	Snapshot
+	Accounts       []persistedAccount `json:"accounts"`
+	LastDeployment *Deployment        `json:"lastDeployment,omitempty"`
+}
+
+type persistedAccount struct {
+	Name       string `json:"name"`
+	Address    string `json:"address"`
+	KeyIndex   int    `json:"keyIndex"`
+	SigAlgo    string `json:"sigAlgo"`
+	HashAlgo   string `json:"hashAlgo"`
+	PrivateKey string `json:"privateKey"`
+}
+
+func (p *Project) repositoryPath() string {
+	return filepath.Join(p.dataDirectory, "repository")
+}
+
+func (p *Project) journalPath() string {
+	return filepath.Join(p.dataDirectory, "chain.journal")
+}
+
+// saveState writes the project state to the data directory, if the project is persisted.
+// Failures are logged, since the in-memory project remains usable.
+func (p *Project) saveState() {
+	if p.dataDirectory == "" || p.kit == nil {
+		return
+	}
+
+	err := p.writeState()
+	if err != nil {
+		p.logger.Error().Err(err).Msg("Failed to save project state")
+	}
+}
+
+func (p *Project) writeState() error {
+	state, err := p.persistedState()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// The state is replaced atomically, so that a crash can't leave a partially written file behind.
+	statePath := filepath.Join(p.dataDirectory, stateFileName)
+	err = os.WriteFile(statePath+".tmp", data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(statePath+".tmp", statePath)
+}
+
+func (p *Project) persistedState() (*persistedState, error) {
+	persistedAccounts, err := p.persistedAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	persistedSnapshots := make([]persistedSnapshot, 0, len(p.snapshots))
+	for _, snapshot := range p.snapshots {
+		snapshotAccounts, err := newPersistedAccounts(snapshot.accounts)
+		if err != nil {
+			return nil, err
+		}
+
+		persistedSnapshots = append(persistedSnapshots, persistedSnapshot{
+			Snapshot:       snapshot.Snapshot,
+			Accounts:       snapshotAccounts,
+			LastDeployment: snapshot.lastDeployment,
+		})
+	}
+
+	sort.Slice(persistedSnapshots, func(i, j int) bool {
+		return persistedSnapshots[i].CreatedAt.Before(persistedSnapshots[j].CreatedAt)
+	})
+
+	return &persistedState{
+		ID:              p.id,
+		ProjectUrl:      p.url,
+		Ref:             p.ref,
+		Directory:       p.directory,
+		Depth:           p.repository.Depth(),
+		AutoDeploy:      p.autoDeploy,
+		TransactionFees: p.blockchainOptions.TransactionFees,
+		StorageLimit:    p.blockchainOptions.StorageLimit,
+		AccessAPI:       p.blockchainOptions.AccessAPI,
+		CreatedAt:       p.createdAt,
+		Accounts:        persistedAccounts,
+		LastDeployment:  p.lastDeployment,
+		Snapshots:       persistedSnapshots,
+	}, nil
+}
+
+// persistedAccounts returns the flow.json accounts with the addresses and keys they were created with on the emulator.
+func (p *Project) persistedAccounts() ([]persistedAccount, error) {
+	kitState, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	return newPersistedAccounts(*kitState.Accounts())
+}
+
+func newPersistedAccounts(kitAccounts accounts.Accounts) ([]persistedAccount, error) {
+	persistedAccounts := make([]persistedAccount, 0, len(kitAccounts))
+	for _, account := range kitAccounts {
+		privateKey, err := account.Key.PrivateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the key of account %s: %w", account.Name, err)
+		}
+
+		persistedAccounts = append(persistedAccounts, persistedAccount{
+			Name:       account.Name,
+			Address:    account.Address.Hex(),
+			KeyIndex:   account.Key.Index(),
+			SigAlgo:    account.Key.SigAlgo().String(),
+			HashAlgo:   account.Key.HashAlgo().String(),
+			PrivateKey: (*privateKey).String(),
+		})
+	}
+
+	return persistedAccounts, nil
+}
+
+// Restore reopens a project persisted to its data directory.
+// The repository and the emulator state are loaded from disk, so contracts aren't redeployed.
+func (p *Project) Restore() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dataDirectory, stateFileName))
+	if err != nil {
+		return err
+	}
+
+	var state persistedState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return fmt.Errorf("invalid project state: %w", err)
+	}
+
+	p.url = state.ProjectUrl
+	p.ref = state.Ref
+	p.directory = state.Directory
+	p.autoDeploy = state.AutoDeploy
+	p.createdAt = state.CreatedAt
+	p.lastDeployment = state.LastDeployment
+	p.blockchainOptions.TransactionFees = state.TransactionFees
+	p.blockchainOptions.StorageLimit = state.StorageLimit
+	p.blockchainOptions.AccessAPI = state.AccessAPI
+
+	err = p.repository.Open(state.Depth)
+	if err != nil {
+		return err
+	}
+
+	err = p.blockchain.Start(p.blockchainOptions)
+	if err != nil {
+		return err
+	}
+
+	kit, err := p.initFlowKit()
+	if err != nil {
+		return err
+	}
+
+	p.kit = kit
+
+	err = p.restoreAccounts(state.Accounts)
+	if err != nil {
+		return err
+	}
+
+	return p.restoreSnapshots(state.Snapshots)
+}
+
+// restoreSnapshots restores the project state of the snapshots, which must be restored after the blockchain.
+// Snapshots whose chain state is missing are skipped.
+func (p *Project) restoreSnapshots(persistedSnapshots []persistedSnapshot) error {
+	names, err := p.blockchain.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	for _, persisted := range persistedSnapshots {
+		if !slices.Contains(names, persisted.Name) {
+			p.logger.Warn().Msg(fmt.Sprintf("Skipped snapshot %s without chain state", persisted.Name))
+			continue
+		}
+
+		snapshotAccounts, err := decodePersistedAccounts(persisted.Accounts)
+		if err != nil {
+			return err
+		}
+
+		p.snapshots[persisted.Name] = &projectSnapshot{
+			Snapshot:       persisted.Snapshot,
+			accounts:       snapshotAccounts,
+			lastDeployment: persisted.LastDeployment,
+		}
+	}
+
+	return nil
+}
+
+// restoreAccounts replaces the flow.json accounts with the accounts that were created on the emulator.
+func (p *Project) restoreAccounts(persistedAccounts []persistedAccount) error {
+	state, err := p.kit.State()
+	if err != nil {
+		return err
+	}
+
+	restoredAccounts, err := decodePersistedAccounts(persistedAccounts)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range restoredAccounts {
+		// See setupAccounts, existing accounts must be removed before they can be updated.
+		_ = state.Accounts().Remove(account.Name)
+
+		state.Accounts().AddOrUpdate(&account)
+	}
+
+	return nil
+}
+
+func decodePersistedAccounts(persistedAccounts []persistedAccount) (accounts.Accounts, error) {
+	decoded := make(accounts.Accounts, 0, len(persistedAccounts))
+
+	for _, persisted := range persistedAccounts {
+		privateKey, err := crypto.DecodePrivateKeyHex(
+			crypto.StringToSignatureAlgorithm(persisted.SigAlgo),
+			strings.TrimPrefix(persisted.PrivateKey, "0x"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key of account %s: %w", persisted.Name, err)
+		}
+
+		// Signing fails unless the public key of a decoded private key was derived, so it is derived upfront.
+		_ = privateKey.PublicKey()
+
+		decoded = append(decoded, accounts.Account{
+			Name:    persisted.Name,
+			Address: flow.HexToAddress(persisted.Address),
+			Key: accounts.NewHexKeyFromPrivateKey(
+				persisted.KeyIndex,
+				crypto.StringToHashAlgorithm(persisted.HashAlgo),
+				privateKey,
+			),
+		})
+	}
+
+	return decoded, nil
+}
@@ -2,7 +2,7 @@ package project
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"fri-flowser-playground/internal/emulator"
 	"fri-flowser-playground/internal/git"
@@ -11,140 +11,574 @@ import (
 	"github.com/onflow/flow-go-sdk/crypto"
 	"github.com/onflow/flowkit"
 	"github.com/onflow/flowkit/accounts"
-	"github.com/onflow/flowkit/arguments"
 	"github.com/onflow/flowkit/output"
 	"github.com/onflow/flowkit/transactions"
 	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+const configFileName = "flow.json"
+
+var ErrConfigNotFound = errors.New("project configuration not found")
+
+// ErrProjectClosed is returned by projects that were closed (i.e. deleted or evicted)
+// while they were still used, e.g. by a request that looked them up before.
+var ErrProjectClosed = errors.New("project is closed")
+
 type Project struct {
-	blockchain *emulator.Blockchain
-	repository *git.Repository
-	logger     *zerolog.Logger
-	kit        *flowkit.Flowkit
+	// mu serializes access to the flowkit state, repository and blockchain,
+	// since none of those are safe for concurrent use.
+	mu        sync.Mutex
+	id        string
+	url       string
+	ref       string
+	directory string
+	createdAt time.Time
+	// lastAccessedAt is the Unix time in nanoseconds of the last use,
+	// which is accessed atomically so that looking up projects doesn't wait for their operations.
+	lastAccessedAt atomic.Int64
+	blockchain     *emulator.Blockchain
+	repository     *git.Repository
+	logger         *zerolog.Logger
+	logs           *LogBuffer
+	kit            *flowkit.Flowkit
+	// autoDeploy redeploys affected contracts whenever a Cadence file or the project configuration changes.
+	autoDeploy     bool
+	lastDeployment *Deployment
+	// blockchainOptions enable mainnet-like fees and storage limits on the emulator.
+	blockchainOptions emulator.Options
+	snapshots         map[string]*projectSnapshot
+	// dataDirectory is where the project is persisted, empty if the project is only kept in memory.
+	dataDirectory string
+	// subscribers is the number of open streams of the project chain or logs, which keep the project from being idle.
+	subscribers atomic.Int64
+	// closed is set once the project is closed, after which its flowkit state, repository and blockchain can't be used.
+	closed bool
+}
+
+type ProjectInfo struct {
+	ID              string    `json:"id"`
+	ProjectUrl      string    `json:"projectUrl"`
+	Ref             string    `json:"ref"`
+	Directory       string    `json:"directory"`
+	Head            string    `json:"head"`
+	AutoDeploy      bool      `json:"autoDeploy"`
+	TransactionFees bool      `json:"transactionFees"`
+	StorageLimit    bool      `json:"storageLimit"`
+	CreatedAt       time.Time `json:"createdAt"`
+	LastAccessedAt  time.Time `json:"lastAccessedAt"`
+	// AccessAPI is set while the project chain is served through the Flow Access APIs.
+	AccessAPI *AccessAPIInfo `json:"accessApi"`
+}
+
+// AccessAPIInfo holds the local addresses (host and port) of the Flow Access APIs of a project.
+type AccessAPIInfo struct {
+	GRPCAddress string `json:"grpcAddress"`
+	RESTAddress string `json:"restAddress"`
+}
+
+// New returns a project that is kept in memory,
+// or persisted to the data directory if one is given.
+func New(id string, logger *zerolog.Logger, logOutput io.Writer, dataDirectory string) *Project {
+	logs := NewLogBuffer(maxLogEntries)
+	projectLogger := logger.Output(zerolog.MultiLevelWriter(logOutput, logs)).With().
+		Str(logProjectFieldName, id).
+		Logger()
+
+	now := time.Now()
+
+	p := &Project{
+		id:            id,
+		createdAt:     now,
+		logger:        &projectLogger,
+		logs:          logs,
+		snapshots:     make(map[string]*projectSnapshot),
+		dataDirectory: dataDirectory,
+	}
+	p.lastAccessedAt.Store(now.UnixNano())
+
+	gitLogger := p.sourceLogger(LogSourceGit)
+	emulatorLogger := p.sourceLogger(LogSourceEmulator)
+
+	repository := git.New(gitLogger)
+	blockchain := emulator.New(emulatorLogger)
+
+	if dataDirectory != "" {
+		repository = git.NewOnDisk(gitLogger, p.repositoryPath())
+		blockchain = emulator.NewPersistent(emulatorLogger, p.journalPath())
+	}
+
+	blockchain.SetAccessTransactionHandler(p.commitAccessTransaction)
+
+	p.repository = repository
+	p.blockchain = blockchain
+
+	return p
+}
+
+// sourceLogger returns a logger for entries written by the given component of the project.
+func (p *Project) sourceLogger(source LogSource) *zerolog.Logger {
+	logger := p.logger.With().Str(logSourceFieldName, string(source)).Logger()
+	return &logger
+}
+
+// Logs returns the kept log entries of the project that match the query.
+// Logs are read without locking the project, so that they can be read during long operations.
+func (p *Project) Logs(query LogQuery) ([]LogEntry, error) {
+	if p.isClosed() {
+		return nil, ErrProjectClosed
+	}
+
+	return p.logs.Entries(query)
+}
+
+// LogsSince returns the log entries matching the query starting at the given ID,
+// the ID to continue from and a channel that is closed when the next entry is written.
+func (p *Project) LogsSince(id uint64, query LogQuery) ([]LogEntry, uint64, <-chan struct{}, error) {
+	if p.isClosed() {
+		return nil, id, nil, ErrProjectClosed
+	}
+
+	return p.logs.Since(id, query)
+}
+
+func (p *Project) ID() string {
+	return p.id
+}
+
+func (p *Project) Info() (ProjectInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ProjectInfo{}, ErrProjectClosed
+	}
+
+	// Head is empty until the repository is cloned.
+	head, _ := p.repository.Head()
+
+	var accessAPI *AccessAPIInfo
+	if api := p.blockchain.AccessAPI(); api != nil {
+		accessAPI = &AccessAPIInfo{
+			GRPCAddress: api.GRPCAddress,
+			RESTAddress: api.RESTAddress,
+		}
+	}
+
+	return ProjectInfo{
+		ID:              p.id,
+		ProjectUrl:      p.url,
+		Ref:             p.ref,
+		Directory:       p.directory,
+		Head:            head,
+		AutoDeploy:      p.autoDeploy,
+		TransactionFees: p.blockchainOptions.TransactionFees,
+		StorageLimit:    p.blockchainOptions.StorageLimit,
+		CreatedAt:       p.createdAt,
+		LastAccessedAt:  time.Unix(0, p.lastAccessedAt.Load()),
+		AccessAPI:       accessAPI,
+	}, nil
+}
+
+// touch marks the project as recently used, which postpones its idle eviction.
+func (p *Project) touch() {
+	p.lastAccessedAt.Store(time.Now().UnixNano())
+}
+
+// Subscribe registers an open stream of the project chain or logs, which is ended when the project is closed.
+// The project isn't idle until the returned function is called when the stream ends.
+func (p *Project) Subscribe() (unsubscribe func()) {
+	p.subscribers.Add(1)
+
+	return func() {
+		p.subscribers.Add(-1)
+		p.touch()
+	}
+}
+
+// Closed returns a channel that is closed when the project is closed, e.g. because it was deleted or evicted.
+func (p *Project) Closed() <-chan struct{} {
+	return p.blockchain.Stopped()
+}
+
+// isClosed reports whether the project is closed without locking it.
+func (p *Project) isClosed() bool {
+	select {
+	case <-p.Closed():
+		return true
+	default:
+		return false
+	}
+}
+
+// idleSince returns how long the project wasn't used.
+// Projects serving the Access APIs or with open streams are never idle,
+// since their use through the APIs and streams isn't tracked.
+func (p *Project) idleSince(now time.Time) time.Duration {
+	if p.subscribers.Load() > 0 || p.blockchain.AccessAPI() != nil {
+		return 0
+	}
+
+	return now.Sub(time.Unix(0, p.lastAccessedAt.Load()))
 }
 
-func New(logger *zerolog.Logger) *Project {
-	repository := git.New(logger)
-	blockchain := emulator.New(logger)
+// SetAccessAPI starts or stops serving the project chain through the Flow Access gRPC and REST APIs,
+// which are reachable on local ports allocated when they are started.
+func (p *Project) SetAccessAPI(enabled bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	return &Project{
-		logger:     logger,
-		repository: repository,
-		blockchain: blockchain,
+	if p.closed {
+		return ErrProjectClosed
 	}
+
+	if enabled {
+		err := p.blockchain.StartAccessAPI()
+		if err != nil {
+			return err
+		}
+	} else {
+		p.blockchain.StopAccessAPI()
+	}
+
+	p.blockchainOptions.AccessAPI = enabled
+	p.saveState()
+
+	return nil
+}
+
+// commitAccessTransaction commits a transaction sent through the Access APIs while holding the project lock,
+// so that it isn't committed during other operations on the project chain (e.g. reverting a snapshot or a checkout).
+func (p *Project) commitAccessTransaction(commit func() error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	return commit()
 }
 
 func (p *Project) Files() ([]git.RepositoryFile, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
 	return p.repository.Files()
 }
 
-func (p *Project) Open(projectUrl string) error {
-	fmt.Printf("Cloning project: %s\n", projectUrl)
+func (p *Project) File(filePath string) (*git.RepositoryFile, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	err := p.repository.Clone(projectUrl)
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
 
-	if err != nil {
-		return err
+	return p.repository.File(cleanFilePath(filePath))
+}
+
+// WriteFile creates or overwrites the file, creating any missing parent directories.
+func (p *Project) WriteFile(filePath string, content []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
 	}
 
-	err = p.blockchain.Start()
+	err := p.repository.WriteFile(cleanFilePath(filePath), content, 0644)
 
 	if err != nil {
 		return err
 	}
 
-	kit, err := p.initFlowKit()
+	p.autoRedeploy(cleanFilePath(filePath))
 
-	if err != nil {
-		return err
+	return nil
+}
+
+func (p *Project) CreateDirectory(dirPath string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
 	}
 
-	p.kit = kit
+	return p.repository.MkdirAll(cleanFilePath(dirPath), os.ModeDir|0755)
+}
 
-	err = p.setupAccounts()
+func (p *Project) RenameFile(fromPath string, toPath string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	err := p.repository.Rename(cleanFilePath(fromPath), cleanFilePath(toPath))
 
 	if err != nil {
 		return err
 	}
 
-	contracts, err := p.kit.DeployProject(context.Background(), flowkit.UpdateExistingContract(true))
+	p.autoRedeploy(cleanFilePath(fromPath), cleanFilePath(toPath))
+
+	return nil
+}
+
+func (p *Project) RemoveFile(filePath string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	cleanPath := cleanFilePath(filePath)
+
+	if cleanPath == "/" {
+		return fmt.Errorf("cannot remove the project root directory")
+	}
+
+	err := p.repository.Remove(cleanPath)
 
 	if err != nil {
 		return err
 	}
 
-	p.logger.Info().Msg(fmt.Sprintf("Deployed %d contracts\n", len(contracts)))
+	p.autoRedeploy(cleanPath)
 
 	return nil
 }
 
-func (p *Project) BlockchainState() ([]byte, error) {
-	return p.blockchain.State()
+func (p *Project) Commit(options git.CommitOptions) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return "", ErrProjectClosed
+	}
+
+	return p.repository.Commit(options)
+}
+
+// Push pushes the current branch to the origin remote, and returns whether the remote branch was updated.
+func (p *Project) Push(credentials git.Credentials) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false, ErrProjectClosed
+	}
+
+	return p.repository.Push(credentials)
+}
+
+func (p *Project) Status() ([]git.FileStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.repository.Status()
 }
 
-func (p *Project) ExecuteScript(code []byte, location string, argsJson string) (res []byte, err error) {
-	var args []cadence.Value
-	if argsJson != "" {
-		args, err = arguments.ParseJSON(argsJson)
+func (p *Project) Diff(paths ...string) ([]git.FileDiff, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.repository.Diff(paths...)
+}
+
+func (p *Project) Log(limit int, filePath string) ([]git.CommitInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.repository.Log(limit, filePath)
+}
+
+func (p *Project) FileAtRevision(revision string, filePath string) (*git.RepositoryFile, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.repository.FileAtRevision(revision, filePath)
+}
+
+// cleanFilePath converts a path relative to the project root to an absolute repository path.
+// Any ".." elements that would escape the project root are dropped.
+func cleanFilePath(filePath string) string {
+	return path.Join("/", filePath)
+}
+
+type OpenOptions struct {
+	ProjectUrl string
+	// Ref is a branch, tag or commit SHA to check out, the default branch is used if empty.
+	Ref string
+	// Directory that contains flow.json, relative to the repository root.
+	Directory string
+	// Depth limits the cloned history to the given number of commits, full history is cloned if zero.
+	Depth int
+	// AutoDeploy redeploys affected contracts whenever project files change.
+	AutoDeploy bool
+	// TransactionFees charges transaction payers for execution, as on mainnet.
+	TransactionFees bool
+	// StorageLimit limits account storage by the account FLOW balance, as on mainnet.
+	StorageLimit bool
+	// AccessAPI serves the project chain through the Flow Access gRPC and REST APIs.
+	AccessAPI bool
+}
+
+func (p *Project) Open(options OpenOptions) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
 	}
+
+	p.logger.Info().Msg(fmt.Sprintf("Cloning project: %s", options.ProjectUrl))
+
+	p.url = options.ProjectUrl
+	p.ref = options.Ref
+	p.directory = cleanFilePath(options.Directory)
+	p.autoDeploy = options.AutoDeploy
+	p.blockchainOptions = emulator.Options{
+		TransactionFees: options.TransactionFees,
+		StorageLimit:    options.StorageLimit,
+		AccessAPI:       options.AccessAPI,
+	}
+
+	err := p.repository.Clone(git.CloneOptions{
+		URL:   options.ProjectUrl,
+		Ref:   options.Ref,
+		Depth: options.Depth,
+	})
+
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	result, err := p.kit.ExecuteScript(
-		context.Background(),
-		flowkit.Script{Code: code, Args: args, Location: location},
-		flowkit.LatestScriptQuery,
-	)
+	err = p.blockchain.Start(p.blockchainOptions)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return []byte(result.String()), err
+	return p.load()
 }
 
-func (p *Project) ExecuteTransaction(code []byte, location string, argsJson string) ([]byte, error) {
-	state, err := p.kit.State()
+// Checkout switches the project to a different branch, tag or commit
+// and deploys the contracts from the new revision.
+// Contracts that fail to deploy don't fail the checkout, they are reported by the last deployment.
+func (p *Project) Checkout(ref string, force bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	err := p.repository.Checkout(ref, force)
+
 	if err != nil {
-		return nil, err
+		return err
 	}
-	serviceAccount, err := state.EmulatorServiceAccount()
 
-	var args []cadence.Value
-	if argsJson != "" {
-		args, err = arguments.ParseJSON(argsJson)
+	p.ref = ref
+
+	return p.load()
+}
+
+// load (re)initializes flowkit from the project configuration,
+// creates the configured accounts and deploys the contracts.
+// Accounts that were already created on the emulator keep their addresses and keys when reloading.
+// Contracts that fail to deploy don't fail the load, they are reported by the last deployment
+// so that they can be fixed and redeployed.
+func (p *Project) load() error {
+	var existingAccounts []persistedAccount
+	if p.kit != nil {
+		var err error
+		existingAccounts, err = p.persistedAccounts()
+		if err != nil {
+			return err
+		}
 	}
+
+	kit, err := p.initFlowKit()
+
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	gasLimit := uint64(1000)
+	p.kit = kit
 
-	_, result, err := p.kit.SendTransaction(
-		context.Background(),
-		transactions.AccountRoles{
-			Proposer: *serviceAccount,
-			// TODO: Populate authorizers depending on the prepare statement argument count
-			Authorizers: []accounts.Account{},
-			Payer:       *serviceAccount,
-		},
-		flowkit.Script{Code: code, Args: args, Location: location},
-		gasLimit,
-	)
+	err = p.restoreAccounts(existingAccounts)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	jsonResult, err := json.Marshal(result)
+	err = p.setupAccounts()
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return jsonResult, err
+	_, err = p.deploy()
+
+	return err
+}
+
+// Close stops the project emulator and releases the in-memory repository.
+// Other methods return ErrProjectClosed once the project is closed.
+func (p *Project) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	p.closed = true
+	p.kit = nil
+	p.blockchain.Stop()
+	p.repository.Close()
+}
+
+func (p *Project) BlockchainState() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.blockchain.State()
 }
 
 // setupAccounts creates account on the network and updates the state
@@ -168,9 +602,15 @@ func (p *Project) setupAccounts() error {
 		}
 		pubKey := (*privateKey).PublicKey()
 
-		p.logger.Info().Msg(fmt.Sprintf("Creating account %s %s", confAccount.Name, confAccount.Address))
+		// Accounts restored from a previous load have a different address than the configured one.
+		address := confAccount.Address
+		if account, err := state.Accounts().ByName(confAccount.Name); err == nil {
+			address = account.Address
+		}
 
-		existingAccount, _ := p.kit.Gateway().GetAccount(context.Background(), confAccount.Address)
+		p.logger.Info().Msg(fmt.Sprintf("Creating account %s %s", confAccount.Name, address))
+
+		existingAccount, _ := p.kit.Gateway().GetAccount(context.Background(), address)
 
 		// Only create non-existing accounts
 		if existingAccount != nil {
@@ -192,6 +632,15 @@ func (p *Project) setupAccounts() error {
 			panic(err)
 		}
 
+		// Accounts are created without any FLOW, which they need to pay for fees and storage.
+		// Funding must happen before the accounts are updated, which invalidates the service account reference.
+		if p.blockchainOptions.TransactionFees || p.blockchainOptions.StorageLimit {
+			err = p.fundAccount(serviceAccount, created.Address, initialAccountBalance)
+			if err != nil {
+				return err
+			}
+		}
+
 		// There is a bug that prevents `AddOrUpdate` from updating an existing record, so we must remove it first.
 		// See: https://github.com/onflow/flowkit/blame/2f09f4a76225d658c31147edc419695efb241e25/accounts/account.go#L188
 		_ = state.Accounts().Remove(confAccount.Name)
@@ -203,16 +652,78 @@ func (p *Project) setupAccounts() error {
 		})
 
 		p.logger.Info().Msg(fmt.Sprintf("Created account %s", created.Address))
+
+	}
+
+	return nil
+}
+
+// initialAccountBalance is the amount of FLOW that configured accounts are funded with
+// when fees or storage limits are enabled.
+const initialAccountBalance = "1000.0"
+
+// fundAccountTransaction transfers FLOW from the signer to the recipient,
+// using the addresses of the core contracts on the emulator.
+const fundAccountTransaction = `
+import FungibleToken from 0xee82856bf20e2aa6
+import FlowToken from 0x0ae53cb6e3f42a79
+
+transaction(amount: UFix64, recipient: Address) {
+    let sentVault: @FungibleToken.Vault
+
+    prepare(signer: AuthAccount) {
+        let vault = signer.borrow<&FlowToken.Vault>(from: /storage/flowTokenVault)
+            ?? panic("Could not borrow the signer's FLOW vault")
+        self.sentVault <- vault.withdraw(amount: amount)
+    }
+
+    execute {
+        let receiver = getAccount(recipient).getCapability(/public/flowTokenReceiver)
+            .borrow<&{FungibleToken.Receiver}>()
+            ?? panic("Could not borrow the recipient's FLOW receiver")
+        receiver.deposit(from: <-self.sentVault)
+    }
+}
+`
+
+func (p *Project) fundAccount(funder *accounts.Account, address flow.Address, amount string) error {
+	value, err := parseFlowAmount(amount)
+	if err != nil {
+		return err
+	}
+
+	_, result, err := p.kit.SendTransaction(
+		context.Background(),
+		transactions.SingleAccountRole(*funder),
+		flowkit.Script{
+			Code: []byte(fundAccountTransaction),
+			Args: []cadence.Value{value, cadence.NewAddress(address)},
+		},
+		flow.DefaultTransactionGasLimit,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to fund account %s: %w", address, result.Error)
 	}
 
 	return nil
 }
 
 func (p *Project) initFlowKit() (*flowkit.Flowkit, error) {
+	projectDirectory := p.repository.Directory(p.directory)
+
+	_, err := projectDirectory.Stat(configFileName)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrConfigNotFound, path.Join(p.directory, configFileName))
+	}
+
 	configFilePaths := []string{
-		"flow.json",
+		configFileName,
 	}
-	state, err := flowkit.Load(configFilePaths, p.repository)
+	state, err := flowkit.Load(configFilePaths, projectDirectory)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +733,7 @@ func (p *Project) initFlowKit() (*flowkit.Flowkit, error) {
 		return nil, err
 	}
 
-	flowKitLogger := newFlowKitLogger(p.logger)
+	flowKitLogger := newFlowKitLogger(p.sourceLogger(LogSourceFlowKit))
 
 	return flowkit.NewFlowkit(state, *network, p.blockchain.Gateway(), flowKitLogger), nil
 }
@@ -0,0 +1,446 @@
+package project
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fri-flowser-playground/internal/git"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/rs/zerolog"
+)
+
+var testLogger = zerolog.Nop()
+
+const testConfig = `{
+	"contracts": {
+		"HelloWorld": "./contracts/HelloWorld.cdc"
+	},
+	"networks": {
+		"emulator": "127.0.0.1:3569"
+	},
+	"accounts": {
+		"emulator-account": {
+			"address": "f8d6e0586b0a20c7",
+			"key": "aff3a277caf2bdd6582c156ae7b07dbca537da7833309de88e56987faa2c0f1b"
+		}
+	},
+	"deployments": {
+		"emulator": {
+			"emulator-account": ["HelloWorld"]
+		}
+	}
+}`
+
+const testContract = `pub contract HelloWorld {
+    pub var greeting: String
+
+    init() {
+        self.greeting = "Hello, World!"
+    }
+}`
+
+const testScript = `import "HelloWorld"
+
+pub fun main(): String {
+    return HelloWorld.greeting
+}`
+
+// testProjectFiles returns the files of a project that deploys a single contract.
+func testProjectFiles() map[string]string {
+	return map[string]string{
+		"flow.json":                testConfig,
+		"contracts/HelloWorld.cdc": testContract,
+		"scripts/get_greeting.cdc": testScript,
+	}
+}
+
+// newTestRemote creates a bare repository in a temporary directory with a single commit of the given files
+// on the master branch, and returns its URL.
+func newTestRemote(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	fs := memfs.New()
+	repository, err := gogit.Init(memory.NewStorage(), fs)
+	if err != nil {
+		t.Fatalf("failed to init repository: %s", err)
+	}
+
+	for name, content := range files {
+		err = util.WriteFile(fs, name, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %s", name, err)
+		}
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree: %s", err)
+	}
+
+	err = worktree.AddWithOptions(&gogit.AddOptions{All: true})
+	if err != nil {
+		t.Fatalf("failed to stage files: %s", err)
+	}
+
+	_, err = worktree.Commit("Initial commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %s", err)
+	}
+
+	remoteDirectory := t.TempDir()
+	_, err = gogit.PlainInit(remoteDirectory, true)
+	if err != nil {
+		t.Fatalf("failed to init remote: %s", err)
+	}
+
+	url := "file://" + remoteDirectory
+
+	_, err = repository.CreateRemote(&config.RemoteConfig{Name: gogit.DefaultRemoteName, URLs: []string{url}})
+	if err != nil {
+		t.Fatalf("failed to add remote: %s", err)
+	}
+
+	err = repository.Push(&gogit.PushOptions{})
+	if err != nil {
+		t.Fatalf("failed to push to remote: %s", err)
+	}
+
+	return url
+}
+
+// newTestRegistry returns an in-memory registry and a project created in it from the given files.
+func newTestRegistry(t *testing.T, files map[string]string) (*Registry, *Project) {
+	t.Helper()
+
+	registry := NewRegistry(&testLogger, io.Discard, time.Hour, "")
+
+	p, err := registry.Create(OpenOptions{ProjectUrl: newTestRemote(t, files)})
+	if err != nil {
+		t.Fatalf("failed to create project: %s", err)
+	}
+
+	return registry, p
+}
+
+// TestClosedProject uses a project that was looked up before it was deleted,
+// as a request does when the project is deleted while the request is handled.
+func TestClosedProject(t *testing.T) {
+	registry, p := newTestRegistry(t, testProjectFiles())
+
+	held, err := registry.Get(p.ID())
+	if err != nil {
+		t.Fatalf("failed to get project: %s", err)
+	}
+
+	err = registry.Delete(p.ID())
+	if err != nil {
+		t.Fatalf("failed to delete project: %s", err)
+	}
+
+	calls := map[string]func() error{
+		"Info": func() error {
+			_, err := held.Info()
+			return err
+		},
+		"Files": func() error {
+			_, err := held.Files()
+			return err
+		},
+		"WriteFile": func() error {
+			return held.WriteFile("contracts/HelloWorld.cdc", []byte(testContract))
+		},
+		"Deploy": func() error {
+			_, err := held.Deploy()
+			return err
+		},
+		"LastDeployment": func() error {
+			_, err := held.LastDeployment()
+			return err
+		},
+		"Accounts": func() error {
+			_, err := held.Accounts()
+			return err
+		},
+		"Blocks": func() error {
+			_, err := held.Blocks(ChainQuery{})
+			return err
+		},
+		"ExecuteScriptFile": func() error {
+			_, err := held.ExecuteScriptFile("scripts/get_greeting.cdc", "", ScriptOptions{})
+			return err
+		},
+		"Snapshots": func() error {
+			_, err := held.Snapshots()
+			return err
+		},
+		"CreateSnapshot": func() error {
+			_, err := held.CreateSnapshot("closed")
+			return err
+		},
+		"Status": func() error {
+			_, err := held.Status()
+			return err
+		},
+		"Logs": func() error {
+			_, err := held.Logs(LogQuery{})
+			return err
+		},
+		"SetAccessAPI": func() error {
+			return held.SetAccessAPI(true)
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			if !errors.Is(err, ErrProjectClosed) {
+				t.Errorf("expected ErrProjectClosed, got %v", err)
+			}
+		})
+	}
+
+	// Closing again has no effect.
+	held.Close()
+}
+
+// TestCloseDuringRequests deletes a project while other goroutines keep using it,
+// which must only fail their calls with ErrProjectClosed.
+func TestCloseDuringRequests(t *testing.T) {
+	registry, p := newTestRegistry(t, testProjectFiles())
+
+	requests := []func() error{
+		func() error {
+			_, err := p.Accounts()
+			return err
+		},
+		func() error {
+			_, err := p.Blocks(ChainQuery{})
+			return err
+		},
+		func() error {
+			_, err := p.ExecuteScriptFile("scripts/get_greeting.cdc", "", ScriptOptions{})
+			return err
+		},
+		func() error {
+			return p.WriteFile("scripts/get_greeting.cdc", []byte(testScript))
+		},
+	}
+
+	var started sync.WaitGroup
+	var done sync.WaitGroup
+
+	for _, request := range requests {
+		started.Add(1)
+		done.Add(1)
+
+		go func(request func() error) {
+			defer done.Done()
+
+			first := true
+			for {
+				err := request()
+
+				if first {
+					started.Done()
+					first = false
+				}
+
+				if errors.Is(err, ErrProjectClosed) {
+					return
+				}
+
+				if err != nil {
+					t.Errorf("request failed before the project was closed: %s", err)
+					return
+				}
+			}
+		}(request)
+	}
+
+	started.Wait()
+
+	err := registry.Delete(p.ID())
+	if err != nil {
+		t.Fatalf("failed to delete project: %s", err)
+	}
+
+	done.Wait()
+}
+
+// contractStatuses returns the status of each contract of the deployment by name.
+func contractStatuses(deployment *Deployment) map[string]ContractDeploymentStatus {
+	statuses := make(map[string]ContractDeploymentStatus)
+	for _, contract := range deployment.Contracts {
+		statuses[contract.Name] = contract.Status
+	}
+	return statuses
+}
+
+// TestCreateWithFailedDeployment creates a project with a contract that doesn't compile,
+// which is kept so that the contract can be fixed and redeployed.
+func TestCreateWithFailedDeployment(t *testing.T) {
+	files := testProjectFiles()
+	files["contracts/HelloWorld.cdc"] = `pub contract HelloWorld { pub var greeting: String }`
+
+	registry, p := newTestRegistry(t, files)
+
+	if _, err := registry.Get(p.ID()); err != nil {
+		t.Fatalf("project with a failed deployment isn't registered: %s", err)
+	}
+
+	deployment, err := p.LastDeployment()
+	if err != nil {
+		t.Fatalf("failed to get last deployment: %s", err)
+	}
+
+	if deployment == nil || contractStatuses(deployment)["HelloWorld"] != ContractFailed {
+		t.Fatalf("expected the failed deployment of HelloWorld to be recorded, got %+v", deployment)
+	}
+
+	err = p.WriteFile("contracts/HelloWorld.cdc", []byte(testContract))
+	if err != nil {
+		t.Fatalf("failed to fix contract: %s", err)
+	}
+
+	deployment, err = p.Deploy()
+	if err != nil {
+		t.Fatalf("failed to redeploy: %s", err)
+	}
+
+	if status := contractStatuses(deployment)["HelloWorld"]; status == ContractFailed {
+		t.Errorf("expected the fixed contract to deploy, got status %s", status)
+	}
+}
+
+// TestCheckoutWithFailedDeployment checks out a commit with a contract that doesn't compile,
+// which switches the project to the commit and reports the failed deployment.
+func TestCheckoutWithFailedDeployment(t *testing.T) {
+	_, p := newTestRegistry(t, testProjectFiles())
+
+	initial, err := p.Info()
+	if err != nil {
+		t.Fatalf("failed to get project info: %s", err)
+	}
+
+	err = p.WriteFile("contracts/HelloWorld.cdc", []byte(`pub contract HelloWorld { pub var greeting: String }`))
+	if err != nil {
+		t.Fatalf("failed to break contract: %s", err)
+	}
+
+	broken, err := p.Commit(git.CommitOptions{AuthorName: "Test", AuthorEmail: "test@example.com", Message: "Break contract"})
+	if err != nil {
+		t.Fatalf("failed to commit: %s", err)
+	}
+
+	err = p.Checkout(initial.Head, false)
+	if err != nil {
+		t.Fatalf("failed to check out initial commit: %s", err)
+	}
+
+	err = p.Checkout(broken, false)
+	if err != nil {
+		t.Fatalf("expected checkout with a failed deployment to succeed, got %s", err)
+	}
+
+	info, err := p.Info()
+	if err != nil {
+		t.Fatalf("failed to get project info: %s", err)
+	}
+
+	if info.Head != broken || info.Ref != broken {
+		t.Errorf("expected project to be at %s, got head %s and ref %s", broken, info.Head, info.Ref)
+	}
+
+	deployment, err := p.LastDeployment()
+	if err != nil {
+		t.Fatalf("failed to get last deployment: %s", err)
+	}
+
+	if status := contractStatuses(deployment)["HelloWorld"]; status != ContractFailed {
+		t.Errorf("expected the failed deployment of HelloWorld to be reported, got status %s", status)
+	}
+}
+
+// TestAccessTransactionsWaitForProject commits a transaction sent through the Access APIs
+// while another operation holds the project, which must wait for it to finish.
+func TestAccessTransactionsWaitForProject(t *testing.T) {
+	registry, p := newTestRegistry(t, testProjectFiles())
+
+	p.mu.Lock()
+
+	var committed atomic.Bool
+	result := make(chan error)
+	go func() {
+		result <- p.commitAccessTransaction(func() error {
+			committed.Store(true)
+			return nil
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if committed.Load() {
+		t.Errorf("transaction was committed while the project was locked")
+	}
+
+	p.mu.Unlock()
+
+	if err := <-result; err != nil {
+		t.Fatalf("failed to commit transaction: %s", err)
+	}
+
+	if !committed.Load() {
+		t.Errorf("transaction wasn't committed after the project was unlocked")
+	}
+
+	err := registry.Delete(p.ID())
+	if err != nil {
+		t.Fatalf("failed to delete project: %s", err)
+	}
+
+	err = p.commitAccessTransaction(func() error {
+		t.Errorf("transaction was committed to a closed project")
+		return nil
+	})
+	if !errors.Is(err, ErrProjectClosed) {
+		t.Errorf("expected ErrProjectClosed, got %v", err)
+	}
+}
+
+// TestCreateAccountWithFailedFunding creates an account with a larger balance than the service account holds,
+// which keeps the created account and reports the funding error with it.
+func TestCreateAccountWithFailedFunding(t *testing.T) {
+	_, p := newTestRegistry(t, testProjectFiles())
+
+	created, err := p.CreateAccount(CreateAccountOptions{Name: "alice", Balance: "100000000000.0"})
+	if err != nil {
+		t.Fatalf("expected account to be created, got %s", err)
+	}
+
+	if created.FundingError == "" {
+		t.Errorf("expected funding error to be reported, got balance %s", created.Balance)
+	}
+
+	account, err := p.Account(created.Address)
+	if err != nil {
+		t.Fatalf("failed to get created account: %s", err)
+	}
+
+	if account.Name != "alice" {
+		t.Errorf("expected created account to be named alice, got %q", account.Name)
+	}
+
+	if _, err := p.FundAccount(created.Address, "10.0"); err != nil {
+		t.Errorf("failed to fund created account: %s", err)
+	}
+}
@@ -0,0 +1,322 @@
+package project
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+// Registry holds all open projects, keyed by their generated ID.
+// Projects that weren't accessed for longer than the idle timeout are closed and removed.
+// If the registry has a data directory, projects are persisted to it,
+// idle projects are only closed and are reopened from disk when they are accessed again.
+type Registry struct {
+	mu            sync.RWMutex
+	logger        *zerolog.Logger
+	projects      map[string]*Project
+	idleTimeout   time.Duration
+	dataDirectory string
+	// logOutput is where project logs are written, besides the log buffer of each project.
+	logOutput io.Writer
+}
+
+// NewRegistry returns a registry that keeps projects in memory if the data directory is empty.
+func NewRegistry(logger *zerolog.Logger, logOutput io.Writer, idleTimeout time.Duration, dataDirectory string) *Registry {
+	return &Registry{
+		logger:        logger,
+		logOutput:     logOutput,
+		projects:      make(map[string]*Project),
+		idleTimeout:   idleTimeout,
+		dataDirectory: dataDirectory,
+	}
+}
+
+// Create opens a new project from the given repository and registers it.
+func (r *Registry) Create(options OpenOptions) (*Project, error) {
+	id, err := newProjectID()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := New(id, r.logger, r.logOutput, r.projectDirectory(id))
+
+	// Opening clones the repository and deploys contracts, which can take a while,
+	// so it must not be done while holding the registry lock.
+	err = p.Open(options)
+
+	if err != nil {
+		p.Close()
+		r.removeProjectDirectory(id)
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.projects[id] = p
+	r.mu.Unlock()
+
+	r.logger.Info().Msg(fmt.Sprintf("Created project %s", id))
+
+	return p, nil
+}
+
+// Import creates a new project from a project bundle and registers it.
+func (r *Registry) Import(bundle io.Reader) (*Project, error) {
+	id, err := newProjectID()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := New(id, r.logger, r.logOutput, r.projectDirectory(id))
+
+	err = p.Import(bundle)
+
+	if err != nil {
+		p.Close()
+		r.removeProjectDirectory(id)
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.projects[id] = p
+	r.mu.Unlock()
+
+	r.logger.Info().Msg(fmt.Sprintf("Imported project %s", id))
+
+	return p, nil
+}
+
+// Get returns the project with the given ID and marks it as recently used.
+func (r *Registry) Get(id string) (*Project, error) {
+	r.mu.RLock()
+	p, ok := r.projects[id]
+	r.mu.RUnlock()
+
+	if !ok && r.dataDirectory != "" {
+		return r.restore(id)
+	}
+
+	if !ok {
+		return nil, ErrProjectNotFound
+	}
+
+	p.touch()
+
+	return p, nil
+}
+
+// List returns all projects, ordered by creation time.
+// Persisted projects that were evicted are restored, those that fail to restore are logged and skipped.
+func (r *Registry) List() []*Project {
+	err := r.RestoreAll()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to restore projects")
+	}
+
+	r.mu.RLock()
+	projects := make([]*Project, 0, len(r.projects))
+	for _, p := range r.projects {
+		projects = append(projects, p)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].createdAt.Before(projects[j].createdAt)
+	})
+
+	return projects
+}
+
+// Delete removes the project and tears down its emulator and repository.
+// Persisted projects that were evicted are removed from the data directory without being restored.
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	p, ok := r.projects[id]
+	delete(r.projects, id)
+	r.mu.Unlock()
+
+	if !ok && !r.isPersisted(id) {
+		return ErrProjectNotFound
+	}
+
+	if ok {
+		p.Close()
+	}
+	r.removeProjectDirectory(id)
+
+	r.logger.Info().Msg(fmt.Sprintf("Deleted project %s", id))
+
+	return nil
+}
+
+// EvictIdle closes and removes all projects that were idle for longer than the idle timeout.
+// Persisted projects are kept on disk.
+func (r *Registry) EvictIdle() {
+	now := time.Now()
+
+	r.mu.Lock()
+	evicted := make([]*Project, 0)
+	for id, p := range r.projects {
+		if p.idleSince(now) > r.idleTimeout {
+			evicted = append(evicted, p)
+			delete(r.projects, id)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, p := range evicted {
+		p.Close()
+		r.logger.Info().Msg(fmt.Sprintf("Evicted idle project %s", p.id))
+	}
+}
+
+// StartEviction periodically evicts idle projects until the returned stop function is called.
+func (r *Registry) StartEviction(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				r.EvictIdle()
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// RestoreAll reopens all projects persisted to the data directory that aren't open.
+// Projects that fail to restore are logged and skipped.
+func (r *Registry) RestoreAll() error {
+	if r.dataDirectory == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(r.dataDirectory)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		_, err := r.restore(entry.Name())
+		if err != nil {
+			r.logger.Error().Err(err).Msg(fmt.Sprintf("Failed to restore project %s", entry.Name()))
+		}
+	}
+
+	return nil
+}
+
+// restore reopens the persisted project with the given ID and registers it, unless it's already open.
+func (r *Registry) restore(id string) (*Project, error) {
+	r.mu.RLock()
+	existing, ok := r.projects[id]
+	r.mu.RUnlock()
+
+	if ok {
+		return existing, nil
+	}
+
+	if !r.isPersisted(id) {
+		return nil, ErrProjectNotFound
+	}
+
+	p := New(id, r.logger, r.logOutput, r.projectDirectory(id))
+
+	err := p.Restore()
+
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+
+	r.mu.Lock()
+	existing, ok = r.projects[id]
+	if !ok {
+		r.projects[id] = p
+	}
+	r.mu.Unlock()
+
+	// The project was restored concurrently, e.g. by two requests for an evicted project.
+	if ok {
+		p.Close()
+		existing.touch()
+		return existing, nil
+	}
+
+	r.logger.Info().Msg(fmt.Sprintf("Restored project %s", id))
+
+	return p, nil
+}
+
+// projectDirectory returns the directory the project is persisted to, empty if projects aren't persisted.
+func (r *Registry) projectDirectory(id string) string {
+	if r.dataDirectory == "" {
+		return ""
+	}
+
+	return filepath.Join(r.dataDirectory, id)
+}
+
+// isPersisted returns whether the project with the given ID is persisted to the data directory.
+func (r *Registry) isPersisted(id string) bool {
+	if r.dataDirectory == "" || !isProjectID(id) {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(r.projectDirectory(id), stateFileName))
+	return err == nil
+}
+
+func (r *Registry) removeProjectDirectory(id string) {
+	if r.dataDirectory == "" {
+		return
+	}
+
+	err := os.RemoveAll(r.projectDirectory(id))
+	if err != nil {
+		r.logger.Error().Err(err).Msg(fmt.Sprintf("Failed to remove project data %s", id))
+	}
+}
+
+func isProjectID(id string) bool {
+	_, err := hex.DecodeString(id)
+	return id != "" && err == nil
+}
+
+func newProjectID() (string, error) {
+	b := make([]byte, 8)
+
+	_, err := rand.Read(b)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,120 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fri-flowser-playground/internal/emulator"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-emulator/types"
+	"github.com/onflow/flowkit"
+)
+
+var ErrBlockNotFound = errors.New("block not found")
+
+type ScriptOptions struct {
+	// BlockHeight is the height of the block whose state the script is executed against,
+	// the latest block is used if nil.
+	BlockHeight *uint64
+	// Simplified additionally returns the result as plain JSON.
+	Simplified bool
+	// NamedArguments are simplified arguments keyed by parameter name, used instead of the JSON-Cadence arguments if set.
+	NamedArguments map[string]json.RawMessage
+}
+
+type ScriptResult struct {
+	// Value is encoded as JSON-Cadence, omitted if the script failed.
+	Value json.RawMessage `json:"value,omitempty"`
+	// SimplifiedValue is the value as plain JSON, only set if requested.
+	SimplifiedValue any           `json:"simplifiedValue,omitempty"`
+	Error           *CadenceError `json:"error,omitempty"`
+	// Logs is the output of the Cadence log() calls of the script, also set if the script failed.
+	Logs []string `json:"logs"`
+}
+
+func (p *Project) ExecuteScript(code []byte, location string, argsJson string, options ScriptOptions) (*ScriptResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.executeScript(code, location, argsJson, options)
+}
+
+// ExecuteScriptFile executes the script stored at the given repository path.
+func (p *Project) ExecuteScriptFile(filePath string, argsJson string, options ScriptOptions) (*ScriptResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	code, err := p.repository.ReadFile(cleanFilePath(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	return p.executeScript(code, filePath, argsJson, options)
+}
+
+func (p *Project) executeScript(code []byte, location string, argsJson string, options ScriptOptions) (*ScriptResult, error) {
+	args, err := parseArguments(code, argsJson, options.NamedArguments)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err = p.resolveImports(code, location)
+	if err != nil {
+		return nil, err
+	}
+
+	query := flowkit.LatestScriptQuery
+	if options.BlockHeight != nil {
+		query = flowkit.ScriptQuery{Height: *options.BlockHeight}
+	}
+
+	logs := make([]string, 0)
+
+	value, err := p.kit.ExecuteScript(
+		emulator.WithScriptLogs(context.Background(), &logs),
+		flowkit.Script{Code: code, Args: args, Location: location},
+		query,
+	)
+
+	var scriptError *emulator.ScriptError
+	if errors.As(err, &scriptError) {
+		return &ScriptResult{
+			Error: newCadenceError(scriptError.Err, p.programLocations(scriptError.Location, location)),
+			Logs:  logs,
+		}, nil
+	}
+
+	var blockNotFound *types.BlockNotFoundByHeightError
+	if errors.As(err, &blockNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrBlockNotFound, blockNotFound)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := jsoncdc.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScriptResult{
+		Value: encoded,
+		Logs:  logs,
+	}
+
+	if options.Simplified {
+		result.SimplifiedValue = simpleValue(value)
+	}
+
+	return result, nil
+}
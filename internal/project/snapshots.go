@@ -0,0 +1,154 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onflow/flowkit/accounts"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+var ErrSnapshotExists = errors.New("snapshot already exists")
+var ErrInvalidSnapshotName = errors.New("invalid snapshot name")
+
+// Snapshot is a named checkpoint of the project chain state, which the project can be reverted to.
+type Snapshot struct {
+	Name        string    `json:"name"`
+	BlockHeight uint64    `json:"blockHeight"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// projectSnapshot additionally holds the project state that must match the chain state,
+// since the addresses of the flow.json accounts are assigned when the accounts are created on chain.
+type projectSnapshot struct {
+	Snapshot
+	accounts       accounts.Accounts
+	lastDeployment *Deployment
+}
+
+func (p *Project) Snapshots() ([]Snapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	snapshots := make([]Snapshot, 0, len(p.snapshots))
+	for _, snapshot := range p.snapshots {
+		snapshots = append(snapshots, snapshot.Snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// CreateSnapshot saves the current chain state under the given name.
+func (p *Project) CreateSnapshot(name string) (*Snapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSnapshotName, name)
+	}
+
+	if _, ok := p.snapshots[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotExists, name)
+	}
+
+	state, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	blockHeight, err := p.blockchain.LatestBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.blockchain.CreateSnapshot(name)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &projectSnapshot{
+		Snapshot: Snapshot{
+			Name:        name,
+			BlockHeight: blockHeight,
+			CreatedAt:   time.Now(),
+		},
+		accounts:       slices.Clone(*state.Accounts()),
+		lastDeployment: p.lastDeployment,
+	}
+	p.snapshots[name] = snapshot
+	p.saveState()
+
+	return &snapshot.Snapshot, nil
+}
+
+// RevertSnapshot reverts the chain state to the snapshot with the given name.
+// The snapshot is kept, so that the project can be reverted to it again.
+func (p *Project) RevertSnapshot(name string) (*Snapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	snapshot, ok := p.snapshots[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+	}
+
+	state, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.blockchain.LoadSnapshot(name)
+	if err != nil {
+		return nil, err
+	}
+
+	*state.Accounts() = slices.Clone(snapshot.accounts)
+	p.lastDeployment = snapshot.lastDeployment
+	p.saveState()
+
+	p.logger.Info().Msg(fmt.Sprintf("Reverted to snapshot %s at block height %d", name, snapshot.BlockHeight))
+
+	return &snapshot.Snapshot, nil
+}
+
+func (p *Project) DeleteSnapshot(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProjectClosed
+	}
+
+	if _, ok := p.snapshots[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+	}
+
+	err := p.blockchain.DeleteSnapshot(name)
+	if err != nil {
+		return err
+	}
+
+	delete(p.snapshots, name)
+	p.saveState()
+
+	return nil
+}
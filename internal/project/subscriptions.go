@@ -0,0 +1,139 @@
+package project
+
+import (
+	"context"
+	flowgo "github.com/onflow/flow-go/model/flow"
+)
+
+// maxChainUpdates is the maximum number of blocks returned by ChainUpdates at once,
+// so that the project isn't locked for long when a subscriber catches up on many blocks.
+const maxChainUpdates = 100
+
+// ChainUpdate is a committed block, with its transaction results and events that match the subscription query.
+type ChainUpdate struct {
+	Block              ChainBlock               `json:"block"`
+	TransactionResults []ChainTransactionResult `json:"transactionResults"`
+	Events             []ChainEvent             `json:"events"`
+}
+
+// ChainUpdates returns the blocks committed starting at the given height, or after the latest block if nil,
+// from the oldest to the newest. The event type, status, address and transaction ID of the query filter
+// the transaction results and events, as in TransactionResults and Events, while its heights and pagination are ignored.
+// It also returns the height to continue from and a channel that is closed when the next block is committed.
+// If the chain was reverted to a snapshot below the given height, it continues after the latest block.
+func (p *Project) ChainUpdates(height *uint64, query ChainQuery) ([]ChainUpdate, uint64, <-chan struct{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, 0, nil, ErrProjectClosed
+	}
+
+	if err := query.validate(); err != nil {
+		return nil, 0, nil, err
+	}
+
+	// The channel is taken before reading the blocks, so that no commit is missed in between.
+	committed := p.blockchain.Committed()
+
+	latestHeight, err := p.blockchain.LatestBlockHeight()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	startHeight := latestHeight + 1
+	if height != nil && *height <= startHeight {
+		startHeight = *height
+	}
+
+	if startHeight > latestHeight {
+		return nil, startHeight, committed, nil
+	}
+
+	endHeight := min(latestHeight, startHeight+maxChainUpdates-1)
+	updates := make([]ChainUpdate, 0, endHeight-startHeight+1)
+
+	for blockHeight := startHeight; blockHeight <= endHeight; blockHeight++ {
+		update, err := p.chainUpdate(blockHeight, query)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+
+		updates = append(updates, *update)
+	}
+
+	return updates, endHeight + 1, committed, nil
+}
+
+func (p *Project) chainUpdate(height uint64, query ChainQuery) (*ChainUpdate, error) {
+	store := p.blockchain.Storage()
+
+	block, err := store.BlockByHeight(context.Background(), height)
+	if err != nil {
+		return nil, err
+	}
+
+	chainBlock, err := newChainBlock(store, block)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &ChainUpdate{
+		Block:              chainBlock,
+		TransactionResults: make([]ChainTransactionResult, 0),
+		Events:             make([]ChainEvent, 0),
+	}
+
+	matches := make(map[flowgo.Identifier]bool)
+
+	for _, guarantee := range block.Payload.Guarantees {
+		collection, err := store.CollectionByID(context.Background(), guarantee.CollectionID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range collection.Transactions {
+			transaction, err := loadChainTransaction(store, id)
+			if err != nil {
+				return nil, err
+			}
+
+			matches[id] = transaction.matches(query)
+			if !matches[id] || (query.TransactionID != "" && id.String() != query.TransactionID) {
+				continue
+			}
+
+			result, err := newChainTransactionResult(*transaction)
+			if err != nil {
+				return nil, err
+			}
+
+			update.TransactionResults = append(update.TransactionResults, result)
+		}
+	}
+
+	events, err := store.EventsByHeight(context.Background(), height, query.EventType)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		if query.TransactionID != "" && event.TransactionID.String() != query.TransactionID {
+			continue
+		}
+
+		// Events of system transactions, which aren't stored, only match without status and address filters.
+		if match, ok := matches[event.TransactionID]; ok && !match || !ok && (query.Status != "" || query.Address != "") {
+			continue
+		}
+
+		chainEvent, err := newChainEvent(block, event)
+		if err != nil {
+			return nil, err
+		}
+
+		update.Events = append(update.Events, chainEvent)
+	}
+
+	return update, nil
+}
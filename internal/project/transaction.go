@@ -0,0 +1,259 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit"
+	"github.com/onflow/flowkit/accounts"
+	"github.com/onflow/flowkit/transactions"
+	"strings"
+)
+
+var ErrInvalidTransaction = errors.New("invalid transaction")
+
+type TransactionOptions struct {
+	// Authorizers are names of flow.json accounts that sign the transaction,
+	// one for each prepare() parameter. The service account authorizes all of them if empty.
+	Authorizers []string
+	// Proposer and Payer are names of flow.json accounts, the service account is used if empty.
+	Proposer string
+	Payer    string
+	// ComputeLimit is the maximum computation the transaction may use, the SDK default is used if zero.
+	ComputeLimit uint64
+	// NamedArguments are simplified arguments keyed by parameter name, used instead of the JSON-Cadence arguments if set.
+	NamedArguments map[string]json.RawMessage
+}
+
+type TransactionResult struct {
+	ID string `json:"id"`
+	// Status is the Flow transaction status, e.g. "SEALED".
+	Status      string  `json:"status"`
+	BlockID     string  `json:"blockId"`
+	BlockHeight uint64  `json:"blockHeight"`
+	Events      []Event `json:"events"`
+	// Error is set if the transaction failed, in which case all its changes were reverted.
+	Error           *CadenceError `json:"error,omitempty"`
+	ComputeLimit    uint64        `json:"computeLimit"`
+	ComputationUsed uint64        `json:"computationUsed"`
+	// MemoryEstimate is the estimated memory usage in bytes.
+	MemoryEstimate uint64 `json:"memoryEstimate"`
+	// FeesCharged is the amount of FLOW deducted from the payer, zero if fees are disabled.
+	FeesCharged string `json:"feesCharged"`
+	// Logs is the output of the Cadence log() calls of the transaction, which is also stored with its result.
+	Logs []string `json:"logs"`
+}
+
+// feesDeductedEventSuffix matches the FlowFees.FeesDeducted event, regardless of the fees contract address.
+const feesDeductedEventSuffix = ".FlowFees.FeesDeducted"
+
+func (p *Project) ExecuteTransaction(
+	code []byte,
+	location string,
+	argsJson string,
+	options TransactionOptions,
+) (*TransactionResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	return p.executeTransaction(code, location, argsJson, options)
+}
+
+// ExecuteTransactionFile executes the transaction stored at the given repository path.
+func (p *Project) ExecuteTransactionFile(
+	filePath string,
+	argsJson string,
+	options TransactionOptions,
+) (*TransactionResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrProjectClosed
+	}
+
+	code, err := p.repository.ReadFile(cleanFilePath(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	return p.executeTransaction(code, filePath, argsJson, options)
+}
+
+func (p *Project) executeTransaction(
+	code []byte,
+	location string,
+	argsJson string,
+	options TransactionOptions,
+) (*TransactionResult, error) {
+	args, err := parseArguments(code, argsJson, options.NamedArguments)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := p.transactionRoles(code, location, options)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err = p.resolveImports(code, location)
+	if err != nil {
+		return nil, err
+	}
+
+	computeLimit := options.ComputeLimit
+	if computeLimit == 0 {
+		computeLimit = flow.DefaultTransactionGasLimit
+	}
+
+	tx, result, err := p.kit.SendTransaction(
+		context.Background(),
+		*roles,
+		flowkit.Script{Code: code, Args: args, Location: location},
+		computeLimit,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := newEvents(result.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionResult := &TransactionResult{
+		ID:           tx.ID().String(),
+		Status:       result.Status.String(),
+		BlockID:      result.BlockID.String(),
+		BlockHeight:  result.BlockHeight,
+		Events:       events,
+		ComputeLimit: computeLimit,
+		FeesCharged:  cadence.UFix64(0).String(),
+		Logs:         make([]string, 0),
+	}
+
+	if result.Error != nil {
+		transactionResult.Error = newCadenceError(result.Error, p.programLocations(tx.ID().Hex(), location))
+	}
+
+	if execution, ok := p.blockchain.TransactionExecution(tx.ID()); ok {
+		transactionResult.ComputationUsed = execution.ComputationUsed
+		transactionResult.MemoryEstimate = execution.MemoryEstimate
+		if execution.Logs != nil {
+			transactionResult.Logs = execution.Logs
+		}
+	}
+
+	for _, event := range result.Events {
+		if !strings.HasSuffix(event.Type, feesDeductedEventSuffix) {
+			continue
+		}
+
+		if amount := eventField(event.Value, "amount"); amount != nil {
+			transactionResult.FeesCharged = amount.String()
+		}
+	}
+
+	return transactionResult, nil
+}
+
+// transactionRoles resolves the proposer, payer and authorizer accounts for the transaction.
+// The number of authorizers must match the number of prepare() parameters.
+func (p *Project) transactionRoles(code []byte, location string, options TransactionOptions) (*transactions.AccountRoles, error) {
+	authorizerCount, err := prepareParameterCount(code, location)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizerNames := options.Authorizers
+	if len(authorizerNames) == 0 {
+		authorizerNames = make([]string, authorizerCount)
+	}
+
+	if len(authorizerNames) != authorizerCount {
+		return nil, fmt.Errorf(
+			"%w: transaction requires %d authorizers, but %d were provided",
+			ErrInvalidTransaction,
+			authorizerCount,
+			len(authorizerNames),
+		)
+	}
+
+	authorizers := make([]accounts.Account, 0, len(authorizerNames))
+	for _, name := range authorizerNames {
+		authorizer, err := p.accountByName(name)
+		if err != nil {
+			return nil, err
+		}
+		authorizers = append(authorizers, *authorizer)
+	}
+
+	proposer, err := p.accountByName(options.Proposer)
+	if err != nil {
+		return nil, err
+	}
+
+	payer, err := p.accountByName(options.Payer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transactions.AccountRoles{
+		Proposer:    *proposer,
+		Authorizers: authorizers,
+		Payer:       *payer,
+	}, nil
+}
+
+// accountByName returns the flow.json account with the given name, or the service account if the name is empty.
+func (p *Project) accountByName(name string) (*accounts.Account, error) {
+	state, err := p.kit.State()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return state.EmulatorServiceAccount()
+	}
+
+	account, err := state.Accounts().ByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTransaction, err)
+	}
+
+	return account, nil
+}
+
+// prepareParameterCount returns the number of parameters of the transaction prepare() block,
+// which is the number of accounts that must authorize the transaction.
+func prepareParameterCount(code []byte, location string) (int, error) {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		cadenceError := newCadenceError(err, nil)
+		if location != "" {
+			cadenceError.Location = cleanFilePath(location)
+		}
+		return 0, cadenceError
+	}
+
+	transactionDeclarations := program.TransactionDeclarations()
+	if len(transactionDeclarations) != 1 {
+		return 0, fmt.Errorf("%w: code must declare exactly one transaction", ErrInvalidTransaction)
+	}
+
+	prepare := transactionDeclarations[0].Prepare
+	if prepare == nil || prepare.FunctionDeclaration.ParameterList == nil {
+		return 0, nil
+	}
+
+	return len(prepare.FunctionDeclaration.ParameterList.Parameters), nil
+}
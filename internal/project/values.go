@@ -0,0 +1,72 @@
+package project
+
+import (
+	"encoding/json"
+	"github.com/onflow/cadence"
+)
+
+// compositeValue is implemented by structs, resources, events, contracts, enums and attachments.
+type compositeValue interface {
+	GetFields() []cadence.Field
+	GetFieldValues() []cadence.Value
+}
+
+// simpleValue converts a Cadence value to plain JSON, without the type information of JSON-Cadence.
+// Numbers that don't fit into a JavaScript number (64-bit and larger integers, fixed point numbers)
+// are converted to strings, composite values to objects keyed by field name.
+func simpleValue(value cadence.Value) any {
+	switch v := value.(type) {
+	case nil, cadence.Void:
+		return nil
+	case cadence.Optional:
+		return simpleValue(v.Value)
+	case cadence.Bool:
+		return bool(v)
+	case cadence.String:
+		return string(v)
+	case cadence.Character:
+		return string(v)
+	case cadence.Int8, cadence.Int16, cadence.Int32,
+		cadence.UInt8, cadence.UInt16, cadence.UInt32,
+		cadence.Word8, cadence.Word16, cadence.Word32:
+		return json.Number(v.String())
+	case cadence.Array:
+		values := make([]any, 0, len(v.Values))
+		for _, element := range v.Values {
+			values = append(values, simpleValue(element))
+		}
+		return values
+	case cadence.Dictionary:
+		values := make(map[string]any, len(v.Pairs))
+		for _, pair := range v.Pairs {
+			values[simpleKey(pair.Key)] = simpleValue(pair.Value)
+		}
+		return values
+	case compositeValue:
+		fieldValues := v.GetFieldValues()
+		values := make(map[string]any, len(fieldValues))
+		for i, field := range v.GetFields() {
+			if i < len(fieldValues) {
+				values[field.Identifier] = simpleValue(fieldValues[i])
+			}
+		}
+		return values
+	case cadence.TypeValue:
+		if v.StaticType == nil {
+			return nil
+		}
+		return v.StaticType.ID()
+	default:
+		// Remaining values (e.g. addresses, paths, large integers and fixed point numbers)
+		// are represented by their Cadence literal.
+		return value.String()
+	}
+}
+
+// simpleKey converts a dictionary key to a JSON object key.
+func simpleKey(key cadence.Value) string {
+	if s, ok := key.(cadence.String); ok {
+		return string(s)
+	}
+	return key.String()
+}
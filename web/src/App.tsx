@@ -16,12 +16,13 @@ function App() {
     const [projectLogs, setProjectLogs] = useState<ProjectLog[]>();
     const [projectFiles, setProjectFiles] = useState<ProjectFile[]>();
     const [executionResult, setExecutionResult] = useState<unknown>();
+    const [projectId, setProjectId] = useState<string>();
     const service = new ProjectService({ baseUrl: "http://localhost:8080" });
     const urlParams = new URLSearchParams(window.location.search);
     const projectUrl = urlParams.get('projectUrl');
 
     async function onExecute() {
-        if (!openFile) {
+        if (!openFile || !projectId) {
             return;
         }
 
@@ -30,13 +31,13 @@ function App() {
 
         // A very dump heuristic to determine if Cadence code is a transaction or script
         if (isScript) {
-            setExecutionResult(await service.executeScript({
+            setExecutionResult(await service.executeScript(projectId, {
                 source: openFile.content,
                 arguments: args,
                 location: openFile.path
             }))
         } else if (isTransaction) {
-            setExecutionResult(await service.executeTransaction({
+            setExecutionResult(await service.executeTransaction(projectId, {
                 source: openFile.content,
                 arguments: args,
                 location: openFile.path
@@ -47,31 +48,32 @@ function App() {
     useEffect(() => {
         (async function () {
             if (projectUrl) {
-                await service.openProject(projectUrl)
-                setProjectFiles(await service.listProjectFiles())
+                const project = await service.openProject(projectUrl)
+                setProjectId(project.id)
+                setProjectFiles(await service.listProjectFiles(project.id))
             }
         })()
     }, [projectUrl]);
 
     useEffect(() => {
-        if (projectUrl) {
+        if (projectId) {
             const interval = setInterval(async () => {
-                setProjectLogs(await service.listProjectLogs())
+                setProjectLogs(await service.listProjectLogs(projectId, {level: "info"}))
             }, 1000);
 
             return () => clearInterval(interval)
         }
-    }, [projectUrl]);
+    }, [projectId]);
 
     useEffect(() => {
-        if (projectUrl) {
+        if (projectId) {
             const interval = setInterval(async () => {
-                setBlockchainState(await service.getProjectBlockchainState())
+                setBlockchainState(await service.getProjectBlockchainState(projectId))
             }, 1000);
 
             return () => clearInterval(interval)
         }
-    }, [projectUrl]);
+    }, [projectId]);
 
     const beforeEditorMount = (monaco: Monaco) => {
         configureCadence(monaco);
@@ -136,10 +138,9 @@ function App() {
                         <TabsContent value="logs" className="h-full">
                             <pre className='overflow-y-auto h-full'>
                                 {projectLogs
-                                    ?.filter(log => log.level !== "debug")
                                     ?.sort((a, b) => b.time.getTime() - a.time.getTime())
-                                    .map((log, i) => (
-                                        <div key={i}>[{log.level}][{getFormattedTime(log)}] {log.msg}</div>
+                                    .map(log => (
+                                        <div key={log.id}>[{log.level}][{log.source}][{getFormattedTime(log)}] {log.message}</div>
                                     ))}
                             </pre>
                         </TabsContent>
@@ -4,10 +4,42 @@ export type ProjectFile = {
     content: string;
 }
 
-export type ProjectLog = Record<string, unknown> & {
+export type LogSource = "project" | "emulator" | "flowkit" | "git";
+
+export type ProjectLog = {
+    id: number;
     level: string;
     time: Date;
-    msg: string;
+    source: LogSource;
+    message: string;
+    fields?: Record<string, unknown>;
+}
+
+export type LogQuery = {
+    // Minimum level of the entries.
+    level?: string;
+    source?: LogSource;
+    since?: Date;
+    until?: Date;
+    search?: string;
+    after?: number;
+    limit?: number;
+}
+
+export type ProjectInfo = {
+    id: string;
+    projectUrl: string;
+    createdAt: string;
+    lastAccessedAt: string;
+    // Set while the project chain is served through the Flow Access APIs
+    accessApi: AccessApiInfo | null;
+}
+
+export type AccessApiInfo = {
+    // Host and port, e.g. for the Go SDK gRPC client or flow-cli
+    grpcAddress: string;
+    // Host and port, e.g. for the FCL accessNode.api config
+    restAddress: string;
 }
 
 // TODO: Define type if needed later
@@ -19,6 +51,12 @@ type ExecuteScriptRequest = {
     arguments: any;
     // File location (full path)
     location: string;
+    // Block height to execute the script at (defaults to the latest block)
+    blockHeight?: number;
+    // Also return the result as plain JSON
+    simplified?: boolean;
+    // Plain JSON arguments keyed by parameter name, used instead of arguments if set
+    namedArguments?: Record<string, unknown>;
 }
 
 type ExecuteTransactionRequest = {
@@ -27,6 +65,207 @@ type ExecuteTransactionRequest = {
     arguments: any;
     // File location (full path)
     location: string;
+    // Names of flow.json accounts, one for each prepare() parameter (defaults to the service account)
+    authorizers?: string[];
+    proposer?: string;
+    payer?: string;
+    // Maximum computation the transaction may use
+    computeLimit?: number;
+    // Plain JSON arguments keyed by parameter name, used instead of arguments if set
+    namedArguments?: Record<string, unknown>;
+}
+
+export type ProgramParameters = {
+    kind: "script" | "transaction";
+    // Cadence types, e.g. "UFix64" or "[Address]"
+    parameters: { name: string; type: string }[];
+    authorizers: number;
+}
+
+export type CadenceError = {
+    code?: number;
+    message: string;
+    location?: string;
+    line?: number;
+    column?: number;
+}
+
+export type ProjectEvent = {
+    type: string;
+    transactionId: string;
+    transactionIndex: number;
+    eventIndex: number;
+    // Values are encoded using: https://cadence-lang.org/docs/json-cadence-spec
+    fields: { name: string; value: unknown }[];
+}
+
+export type TransactionResult = {
+    id: string;
+    status: string;
+    blockId: string;
+    blockHeight: number;
+    events: ProjectEvent[];
+    error?: CadenceError;
+    computeLimit: number;
+    computationUsed: number;
+    memoryEstimate: number;
+    feesCharged: string;
+    // Output of the Cadence log() calls.
+    logs: string[];
+}
+
+export type ScriptResult = {
+    // Encoded using: https://cadence-lang.org/docs/json-cadence-spec
+    value?: unknown;
+    simplifiedValue?: unknown;
+    error?: CadenceError;
+    // Output of the Cadence log() calls, also set if the script failed.
+    logs: string[];
+}
+
+export type ProjectSnapshot = {
+    name: string;
+    blockHeight: number;
+    createdAt: string;
+}
+
+export type Page<T> = {
+    items: T[];
+    // Number of items matching the query, regardless of offset and limit
+    total: number;
+    offset: number;
+    limit: number;
+}
+
+export type ChainQuery = {
+    offset?: number;
+    limit?: number;
+    // Inclusive block height range
+    startHeight?: number;
+    endHeight?: number;
+    // Fully qualified event type, only applies to events
+    type?: string;
+    status?: TransactionStatus;
+    // Matches transactions proposed, paid for or authorized by the account
+    address?: string;
+    // Only applies to events
+    transactionId?: string;
+}
+
+export type TransactionStatus = "succeeded" | "failed";
+
+export type ChainBlock = {
+    id: string;
+    parentId: string;
+    height: number;
+    timestamp: string;
+    collectionIds: string[];
+    transactionCount: number;
+}
+
+export type ChainCollection = {
+    id: string;
+    blockId: string;
+    blockHeight: number;
+    transactionIds: string[];
+}
+
+export type ChainTransaction = {
+    id: string;
+    blockId: string;
+    blockHeight: number;
+    status: TransactionStatus;
+    script: string;
+    // Encoded using: https://cadence-lang.org/docs/json-cadence-spec
+    arguments: unknown[];
+    referenceBlockId: string;
+    computeLimit: number;
+    proposalKey: { address: string; keyIndex: number; sequenceNumber: number };
+    payer: string;
+    authorizers: string[];
+}
+
+export type ChainTransactionResult = {
+    transactionId: string;
+    blockId: string;
+    blockHeight: number;
+    status: TransactionStatus;
+    error?: CadenceError;
+    logs: string[];
+    events: ProjectEvent[];
+}
+
+export type ChainEvent = ProjectEvent & {
+    blockId: string;
+    blockHeight: number;
+}
+
+export type ChainSubscription = {
+    onBlock?: (block: ChainBlock) => void;
+    onTransactionResult?: (result: ChainTransactionResult) => void;
+    onEvent?: (event: ChainEvent) => void;
+}
+
+export type ProjectAccount = {
+    address: string;
+    // Name of the flow.json account, if any
+    name?: string;
+    // FLOW balance
+    balance: string;
+    keys: AccountKey[];
+    // Code is only included when fetching a single account
+    contracts: { name: string; code?: string }[];
+}
+
+export type AccountKey = {
+    index: number;
+    publicKey: string;
+    sigAlgo: string;
+    hashAlgo: string;
+    weight: number;
+    sequenceNumber: number;
+    revoked: boolean;
+}
+
+export type StorageItem = {
+    // Full path, e.g. "/storage/flowTokenVault"
+    path: string;
+    domain: "storage" | "public" | "private";
+    type: string;
+}
+
+export type StorageValue = StorageItem & {
+    // Encoded using: https://cadence-lang.org/docs/json-cadence-spec
+    value: unknown;
+    simplifiedValue?: unknown;
+}
+
+type CreateAccountRequest = {
+    // Name of the account in flow.json
+    name: string;
+    // Hex encoded private key, a key is generated if omitted
+    privateKey?: string;
+    // Key algorithms, defaults to ECDSA_P256 and SHA3_256
+    sigAlgo?: string;
+    hashAlgo?: string;
+    // Amount of FLOW transferred from the service account
+    balance?: string;
+    // Add the account to flow.json in the repository
+    save?: boolean;
+}
+
+type AddAccountKeyRequest = {
+    // Hex encoded public key
+    publicKey: string;
+    sigAlgo?: string;
+    hashAlgo?: string;
+    // Defaults to full weight (1000)
+    weight?: number;
+}
+
+export type CreatedAccount = ProjectAccount & {
+    // Hex encoded private key that transactions are signed with
+    privateKey: string;
 }
 
 type Config = {
@@ -37,44 +276,261 @@ export class ProjectService {
     constructor(private readonly config: Config) {
     }
 
-    async getProjectBlockchainState(): Promise<BlockchainState> {
-        return fetch(`${this.config.baseUrl}/projects/blockchain-state`).then(res => res.json());
+    async getProjectBlockchainState(projectId: string): Promise<BlockchainState> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/blockchain-state`).then(res => res.json());
+    }
+
+    async listBlocks(projectId: string, query: ChainQuery = {}): Promise<Page<ChainBlock>> {
+        return this.queryChain(projectId, "blocks", query);
     }
 
-    async listProjectLogs(): Promise<ProjectLog[]> {
-        return fetch(`${this.config.baseUrl}/projects/logs`).then(res => res.json()).then(logs => logs.map((log: string) => {
-            const parsedLog = JSON.parse(log);
+    // Accepts either a block height or ID
+    async getBlock(projectId: string, heightOrId: number | string): Promise<ChainBlock> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/chain/blocks/${heightOrId}`).then(res => res.json());
+    }
 
-            return {
-                ...parsedLog,
-                time: new Date(parsedLog.time)
+    async listCollections(projectId: string, query: ChainQuery = {}): Promise<Page<ChainCollection>> {
+        return this.queryChain(projectId, "collections", query);
+    }
+
+    async getCollection(projectId: string, id: string): Promise<ChainCollection> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/chain/collections/${id}`).then(res => res.json());
+    }
+
+    async listTransactions(projectId: string, query: ChainQuery = {}): Promise<Page<ChainTransaction>> {
+        return this.queryChain(projectId, "transactions", query);
+    }
+
+    async getTransaction(projectId: string, id: string): Promise<ChainTransaction> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/chain/transactions/${id}`).then(res => res.json());
+    }
+
+    async listTransactionResults(projectId: string, query: ChainQuery = {}): Promise<Page<ChainTransactionResult>> {
+        return this.queryChain(projectId, "transaction-results", query);
+    }
+
+    async getTransactionResult(projectId: string, id: string): Promise<ChainTransactionResult> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/chain/transaction-results/${id}`).then(res => res.json());
+    }
+
+    async listEvents(projectId: string, query: ChainQuery = {}): Promise<Page<ChainEvent>> {
+        return this.queryChain(projectId, "events", query);
+    }
+
+    // Calls the subscription handlers with blocks as they are committed, followed by their transaction results and events
+    // matching the query. New blocks are streamed, unless the query has a start height, the other heights and pagination are ignored.
+    // EventSource reconnects automatically and resumes after the last received block.
+    // Returns a function that stops the stream.
+    streamChainUpdates(projectId: string, subscription: ChainSubscription, query: Omit<ChainQuery, "offset" | "limit" | "endHeight"> = {}): () => void {
+        const params = new URLSearchParams();
+        for (const [key, value] of Object.entries(query)) {
+            if (value !== undefined) {
+                params.set(key, String(value));
             }
-        }));
+        }
+
+        const source = new EventSource(`${this.config.baseUrl}/projects/${projectId}/chain/stream?${params}`);
+
+        source.addEventListener("block", (event) => subscription.onBlock?.(JSON.parse(event.data)));
+        source.addEventListener("transaction-result", (event) => subscription.onTransactionResult?.(JSON.parse(event.data)));
+        source.addEventListener("event", (event) => subscription.onEvent?.(JSON.parse(event.data)));
+
+        return () => source.close();
     }
 
-    async listProjectFiles(): Promise<ProjectFile[]> {
-        return fetch(`${this.config.baseUrl}/projects/files`).then(res => res.json());
+    private async queryChain<T>(projectId: string, collection: string, query: ChainQuery): Promise<Page<T>> {
+        const params = new URLSearchParams();
+        for (const [key, value] of Object.entries(query)) {
+            if (value !== undefined) {
+                params.set(key, String(value));
+            }
+        }
+
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/chain/${collection}?${params}`).then(res => res.json());
+    }
+
+    async listAccounts(projectId: string): Promise<ProjectAccount[]> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/accounts`).then(res => res.json());
     }
 
-    async openProject(projectUrl: string): Promise<void> {
-        await fetch(`${this.config.baseUrl}/projects`, {
+    async getAccount(projectId: string, address: string): Promise<ProjectAccount> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/accounts/${address}`).then(res => res.json());
+    }
+
+    async createAccount(projectId: string, request: CreateAccountRequest): Promise<CreatedAccount> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/accounts`, {
+            method: "POST",
+            body: JSON.stringify(request)
+        }).then(res => res.json());
+    }
+
+    async fundAccount(projectId: string, address: string, amount: string): Promise<ProjectAccount> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/accounts/${address}/fund`, {
+            method: "POST",
+            body: JSON.stringify({amount})
+        }).then(res => res.json());
+    }
+
+    async addAccountKey(projectId: string, address: string, request: AddAccountKeyRequest): Promise<ProjectAccount> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/accounts/${address}/keys`, {
+            method: "POST",
+            body: JSON.stringify(request)
+        }).then(res => res.json());
+    }
+
+    async revokeAccountKey(projectId: string, address: string, keyIndex: number): Promise<ProjectAccount> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/accounts/${address}/keys/${keyIndex}`, {
+            method: "DELETE"
+        }).then(res => res.json());
+    }
+
+    async listAccountStorage(projectId: string, address: string): Promise<StorageItem[]> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/accounts/${address}/storage`).then(res => res.json());
+    }
+
+    // Path is the full path, e.g. "/storage/flowTokenVault"
+    async getAccountStorageValue(projectId: string, address: string, path: string, simplified = false): Promise<StorageValue> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/accounts/${address}/storage${path}?simplified=${simplified}`).then(res => res.json());
+    }
+
+    async listProjectLogs(projectId: string, query: LogQuery = {}): Promise<ProjectLog[]> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/logs?${logQueryParams(query)}`)
+            .then(res => res.json())
+            .then(logs => logs.map(parseProjectLog));
+    }
+
+    // Calls onLog with kept and new log entries matching the query as they are written, starting at the given offset.
+    // EventSource reconnects automatically and resumes after the last received entry.
+    // Returns a function that stops the stream.
+    streamProjectLogs(projectId: string, onLog: (log: ProjectLog) => void, offset = 0, query: LogQuery = {}): () => void {
+        const params = logQueryParams(query);
+        params.set("offset", String(offset));
+
+        const source = new EventSource(`${this.config.baseUrl}/projects/${projectId}/logs/stream?${params}`);
+
+        source.onmessage = (event) => {
+            onLog(parseProjectLog(JSON.parse(event.data)));
+        };
+
+        return () => source.close();
+    }
+
+    async listProjectFiles(projectId: string): Promise<ProjectFile[]> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/files`).then(res => res.json());
+    }
+
+    async openProject(projectUrl: string): Promise<ProjectInfo> {
+        return fetch(`${this.config.baseUrl}/projects`, {
             method: "POST",
             body: JSON.stringify({projectUrl})
+        }).then(res => res.json());
+    }
+
+    // Starts or stops serving the project chain through the Flow Access gRPC and REST APIs on local ports.
+    async setAccessApi(projectId: string, enabled: boolean): Promise<ProjectInfo> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}`, {
+            method: "PATCH",
+            body: JSON.stringify({accessApi: enabled})
+        }).then(res => res.json());
+    }
+
+    async closeProject(projectId: string): Promise<void> {
+        await fetch(`${this.config.baseUrl}/projects/${projectId}`, {
+            method: "DELETE"
         });
     }
 
-    async executeScript(request: ExecuteScriptRequest): Promise<unknown> {
-        return fetch(`${this.config.baseUrl}/projects/scripts`, {
+    async executeScript(projectId: string, request: ExecuteScriptRequest): Promise<ScriptResult> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/scripts`, {
+            method: "POST",
+            body: JSON.stringify(request)
+        }).then(res => res.json());
+    }
+
+    async executeScriptFile(projectId: string, path: string, request: Omit<ExecuteScriptRequest, "source" | "location">): Promise<ScriptResult> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/scripts/${path}`, {
+            method: "POST",
+            body: JSON.stringify(request)
+        }).then(res => res.json());
+    }
+
+    async executeTransaction(projectId: string, request: ExecuteTransactionRequest): Promise<TransactionResult> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/transactions`, {
             method: "POST",
             body: JSON.stringify(request)
         }).then(res => res.json());
     }
 
-    async executeTransaction(request: ExecuteTransactionRequest): Promise<unknown> {
-        return fetch(`${this.config.baseUrl}/projects/transactions`, {
+    async executeTransactionFile(projectId: string, path: string, request: Omit<ExecuteTransactionRequest, "source" | "location">): Promise<TransactionResult> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/transactions/${path}`, {
             method: "POST",
             body: JSON.stringify(request)
         }).then(res => res.json());
     }
 
+    async getParameters(projectId: string, source: string, location?: string): Promise<ProgramParameters> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/parameters`, {
+            method: "POST",
+            body: JSON.stringify({source, location})
+        }).then(res => res.json());
+    }
+
+    async getFileParameters(projectId: string, path: string): Promise<ProgramParameters> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/parameters/${path}`).then(res => res.json());
+    }
+
+    async listSnapshots(projectId: string): Promise<ProjectSnapshot[]> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/snapshots`).then(res => res.json());
+    }
+
+    async createSnapshot(projectId: string, name: string): Promise<ProjectSnapshot> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/snapshots`, {
+            method: "POST",
+            body: JSON.stringify({name})
+        }).then(res => res.json());
+    }
+
+    async revertSnapshot(projectId: string, name: string): Promise<ProjectSnapshot> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/snapshots/${encodeURIComponent(name)}/revert`, {
+            method: "POST"
+        }).then(res => res.json());
+    }
+
+    async deleteSnapshot(projectId: string, name: string): Promise<void> {
+        await fetch(`${this.config.baseUrl}/projects/${projectId}/snapshots/${encodeURIComponent(name)}`, {
+            method: "DELETE"
+        });
+    }
+
+    // Returns the project bundle (a gzipped tar archive), which can be imported as a new project.
+    async exportProject(projectId: string): Promise<Blob> {
+        return fetch(`${this.config.baseUrl}/projects/${projectId}/export`).then(res => res.blob());
+    }
+
+    async importProject(bundle: Blob): Promise<ProjectInfo> {
+        return fetch(`${this.config.baseUrl}/projects/import`, {
+            method: "POST",
+            body: bundle
+        }).then(res => res.json());
+    }
+
+}
+
+function parseProjectLog(log: Omit<ProjectLog, "time"> & { time: string }): ProjectLog {
+    return {
+        ...log,
+        time: new Date(log.time)
+    };
+}
+
+function logQueryParams(query: LogQuery): URLSearchParams {
+    const params = new URLSearchParams();
+
+    for (const [name, value] of Object.entries(query)) {
+        if (value !== undefined) {
+            params.set(name, value instanceof Date ? value.toISOString() : String(value));
+        }
+    }
+
+    return params;
 }